@@ -0,0 +1,286 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// dcmctl validates, plans and applies GPU config profiles on the local node
+// using the same code paths as the device config manager daemon
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	configmanager "github.com/ROCm/device-config-manager/pkg/config_manager"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: dcmctl <command> [flags]
+
+Commands:
+  validate <config.json>   validate every profile of a config file
+  plan --profile <name>    show the partition changes a profile would make
+  apply --profile <name>   partition the GPUs with a profile
+  status                   show the current partitions and the last result
+  list-profiles            list the profiles of the config file
+
+Run dcmctl <command> -h for the flags of a command.
+`
+
+type command struct {
+	flags *flag.FlagSet
+	// number of positional arguments the command accepts
+	maxArgs int
+	run     func(args []string) error
+}
+
+var (
+	configFile string
+	stateFile  string
+	profile    string
+	gpuCount   int
+	jsonOutput bool
+	verbose    bool
+)
+
+func newFlagSet(name string, withProfile bool) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&configFile, "config", "", fmt.Sprintf("config file holding the GPU config profiles (default %v, or %v when absent)", globals.JsonFilePath, globals.YamlFilePath))
+	fs.StringVar(&stateFile, "state-file", globals.StandaloneStateFilePath, "state file written when running outside k8s")
+	fs.BoolVar(&verbose, "v", false, "print the partitioning logs")
+	if withProfile {
+		fs.StringVar(&profile, "profile", "", "name of the GPU config profile")
+	}
+	return fs
+}
+
+func main() {
+	commands := map[string]command{
+		"validate":      {newFlagSet("validate", false), 1, runValidate},
+		"plan":          {newFlagSet("plan", true), 0, runPlan},
+		"apply":         {newFlagSet("apply", true), 0, runApply},
+		"status":        {newFlagSet("status", false), 0, runStatus},
+		"list-profiles": {newFlagSet("list-profiles", false), 0, runListProfiles},
+	}
+	commands["plan"].flags.BoolVar(&jsonOutput, "json", false, "print the plan as json")
+	commands["validate"].flags.IntVar(&gpuCount, "gpu-count", 0, "number of GPUs to validate against, queried from the node when 0")
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+	args := parseArgs(cmd.flags, os.Args[2:])
+	if len(args) > cmd.maxArgs {
+		fmt.Fprintf(os.Stderr, "unexpected arguments %v\n\n%s", args[cmd.maxArgs:], usage)
+		os.Exit(2)
+	}
+
+	if !verbose {
+		log.SetOutput(io.Discard)
+		log_e.SetOutput(io.Discard)
+	}
+	configmanager.SetConfigFilePath(configFile)
+	configFile = configmanager.ConfigFilePath()
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" {
+		configmanager.EnableStandaloneMode(configmanager.StandaloneConfig{StateFile: stateFile})
+	}
+
+	if err := cmd.run(args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// parseArgs parses the flags of a command and returns its positional
+// arguments, flags may follow them as in validate config.json --gpu-count 8
+func parseArgs(fs *flag.FlagSet, arguments []string) []string {
+	args := []string{}
+	for {
+		fs.Parse(arguments)
+		if fs.NArg() == 0 {
+			return args
+		}
+		args = append(args, fs.Arg(0))
+		arguments = fs.Args()[1:]
+	}
+}
+
+func requireProfile() error {
+	if profile == "" {
+		return fmt.Errorf("--profile is required")
+	}
+	return nil
+}
+
+func runValidate(args []string) error {
+	if len(args) > 0 {
+		configFile = args[0]
+	}
+	cfg, err := configmanager.LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %v", configFile, err)
+	}
+	profiles := cfg.Profiles
+	var caps []types.GPUCapabilities
+	if gpuCount == 0 {
+		caps, err = configmanager.GetCapabilities()
+		if err != nil {
+			return fmt.Errorf("failed to query the GPUs, use --gpu-count to validate offline: %v", err)
+		}
+		gpuCount = len(caps)
+	}
+
+	issues := configmanager.ValidateConfig(cfg, gpuCount, caps)
+	if len(issues) == 0 {
+		fmt.Printf("All %d profiles are valid for %d GPUs\n", len(profiles.ProfilesList), gpuCount)
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEVERITY\tPATH\tMESSAGE")
+	for _, issue := range issues {
+		fmt.Fprintf(w, "%v\t%v\t%v\n", issue.Severity, issue.Path, issue.Message)
+	}
+	w.Flush()
+	if configmanager.HasErrors(issues) {
+		return fmt.Errorf("config has invalid profiles for %d GPUs", gpuCount)
+	}
+	return nil
+}
+
+func runPlan(args []string) error {
+	if err := requireProfile(); err != nil {
+		return err
+	}
+	plan, err := configmanager.PlanProfile(profile)
+	if err != nil {
+		return err
+	}
+	if jsonOutput {
+		out, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GPU\tCURRENT\tTARGET\tACTION\tDRIVER RELOAD")
+	for _, p := range plan.GPUs {
+		action := "none"
+		if p.ComputeChange || p.MemoryChange {
+			action = "partition"
+		} else if p.SettingsChange() {
+			action = "settings"
+		}
+		fmt.Fprintf(w, "%v\t%v-%v\t%v-%v\t%v\t%v\n", p.GpuID, p.CurrentCompute, p.CurrentMemory, p.TargetCompute, p.TargetMemory, action, p.DriverReloadNeeded)
+	}
+	w.Flush()
+	if len(plan.ServicesToStop) > 0 {
+		fmt.Printf("\nServices stopped while partitioning: %v\n", strings.Join(plan.ServicesToStop, ", "))
+	}
+	return nil
+}
+
+func runApply(args []string) error {
+	if err := requireProfile(); err != nil {
+		return err
+	}
+	status, err := configmanager.ApplyProfile(profile)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GPU\tPARTITION\tSTATUS\tMESSAGE")
+	for _, s := range status.GPUStatus {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", s.GpuID, s.PartitionType, s.Status, s.Message)
+	}
+	w.Flush()
+	if err != nil {
+		return fmt.Errorf("partitioning with profile %v failed: %v", profile, err)
+	}
+	fmt.Println(status.Reason)
+	return nil
+}
+
+func runStatus(args []string) error {
+	current, err := configmanager.GetCurrentPartitions()
+	if err != nil {
+		return err
+	}
+	caps, err := configmanager.GetCapabilities()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GPU\tCOMPUTE\tMEMORY\tSUPPORTED COMPUTE\tSUPPORTED MEMORY")
+	for _, p := range current {
+		supportedCompute, supportedMemory := "unknown", "unknown"
+		if p.GpuID < len(caps) {
+			if c := caps[p.GpuID].ComputePartitions; len(c) > 0 {
+				supportedCompute = strings.Join(c, ",")
+			}
+			if m := caps[p.GpuID].MemoryPartitions; len(m) > 0 {
+				supportedMemory = strings.Join(m, ",")
+			}
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", p.GpuID, p.CurrentCompute, p.CurrentMemory, supportedCompute, supportedMemory)
+	}
+	w.Flush()
+
+	state, err := configmanager.ReadNodeState(stateFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %v", stateFile, err)
+	}
+	fmt.Printf("\nProfile:      %v\n", state.PartitionStatus.SelectedProfile)
+	fmt.Printf("State:        %v\n", state.ProfileState)
+	fmt.Printf("Last event:   %v\n", state.LastEvent)
+	fmt.Printf("Reason:       %v\n", state.PartitionStatus.Reason)
+	fmt.Printf("Last updated: %v\n", state.LastUpdated.Format("2006-01-02 15:04:05 MST"))
+	return nil
+}
+
+func runListProfiles(args []string) error {
+	cfg, err := configmanager.LoadConfig(configFile)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %v", configFile, err)
+	}
+	profiles := cfg.Profiles
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROFILE\tPARTITIONS\tSKIPPED GPUS")
+	for _, name := range configmanager.ProfileNames(profiles) {
+		p := profiles.ProfilesList[name]
+		partitions := ""
+		for i, c := range p.GetProfiles() {
+			if i > 0 {
+				partitions += ", "
+			}
+			partitions += fmt.Sprintf("%dx %v-%v", c.NumGPUsAssigned, c.ComputePartition, c.MemoryPartition)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\n", name, partitions, p.GetFilters().GetId())
+	}
+	return w.Flush()
+}
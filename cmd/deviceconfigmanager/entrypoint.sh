@@ -52,7 +52,7 @@ fi
 cd /device-config-manager
 TOP_DIR=$(pwd)
 echo "Current directory: $(pwd)"
-rm -rf $TOP_DIR/bin/device-config-manager-$UBUNTU_VERSION
+rm -rf $TOP_DIR/bin/device-config-manager-$UBUNTU_VERSION $TOP_DIR/bin/dcmctl-$UBUNTU_VERSION
 
 mkdir -p $TOP_DIR/build/assets/
 mkdir -p $TOP_DIR/bin
@@ -68,7 +68,7 @@ elif [ "$DEBIAN_BUILD" == 1 ]; then
 fi
 
 echo "Building DCM binary for $UBUNTU_VERSION"
-go build -ldflags "-s -w -X main.Version=$VERSION -X main.GitCommit=$GIT_COMMIT -X main.BuildDate=$BUILD_DATE " -o dcm_build $TOP_DIR/cmd/deviceconfigmanager/main.go
+go build -tags amdsmi -ldflags "-s -w -X main.Version=$VERSION -X main.GitCommit=$GIT_COMMIT -X main.BuildDate=$BUILD_DATE " -o dcm_build $TOP_DIR/cmd/deviceconfigmanager/main.go
 
 if [ $? -ne 0 ]; then
 echo "DCM build failed. Exiting..."
@@ -78,4 +78,14 @@ fi
 echo "Sucessfully build DCM binary for $UBUNTU_VERSION"
 cp dcm_build $TOP_DIR/bin/device-config-manager-$UBUNTU_VERSION
 
-rm -rf dcm_build $TOP_DIR/build/
\ No newline at end of file
+echo "Building dcmctl binary for $UBUNTU_VERSION"
+go build -tags amdsmi -ldflags "-s -w" -o dcmctl_build $TOP_DIR/cmd/dcmctl/main.go
+
+if [ $? -ne 0 ]; then
+echo "dcmctl build failed. Exiting..."
+exit 1
+fi
+
+cp dcmctl_build $TOP_DIR/bin/dcmctl-$UBUNTU_VERSION
+
+rm -rf dcm_build dcmctl_build $TOP_DIR/build/
\ No newline at end of file
@@ -16,10 +16,12 @@ limitations under the License.
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 
 	configmanager "github.com/ROCm/device-config-manager/pkg/config_manager"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
 )
 
 var (
@@ -29,6 +31,12 @@ var (
 )
 
 func main() {
+	var standaloneCfg configmanager.StandaloneConfig
+	flag.StringVar(&standaloneCfg.Profile, "profile", "", "profile to apply when running outside k8s, overrides -profile-file")
+	flag.StringVar(&standaloneCfg.ProfileFile, "profile-file", globals.StandaloneProfileFilePath, "file holding the profile to apply when running outside k8s")
+	flag.StringVar(&standaloneCfg.StateFile, "state-file", globals.StandaloneStateFilePath, "file the partition status is written to when running outside k8s")
+	flag.BoolVar(&standaloneCfg.PlanOnly, "plan-only", false, "write the partition plan to the state file instead of partitioning when running outside k8s")
+	flag.Parse()
 
 	log.Printf("####### DEVICE CONFIG MANAGER #######")
 	log.Printf("Version : %v", Version)
@@ -38,29 +46,48 @@ func main() {
 	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" {
 		log.Println("Running inside a Kubernetes pod")
 	} else {
-		log.Println("Not running inside a Kubernetes pod")
-		<-make(chan struct{})
+		log.Println("Not running inside a Kubernetes pod, starting in standalone mode")
+		configmanager.EnableStandaloneMode(standaloneCfg)
 	}
 
 	log.Printf("#####################################")
-	//Read profile from node labeller
+	//Read profile from node labeller or the standalone profile file
 	selectedProfile, err := configmanager.GetPartitionProfile()
 	if err != nil {
 		log.Printf("err: %+v", err)
 		return
 	}
 
+	// restart the services of a partition run cut short by a crash, or
+	// resume it when its profile is still selected
+	configmanager.RecoverJournal(selectedProfile)
+
+	// report broken profiles before any of them is selected
+	configmanager.ValidateConfigFile()
+
+	go configmanager.StartHTTPServer()
+
+	go configmanager.StartGRPCServer()
+
 	// Start the worker routine
 	go configmanager.Worker()
 
 	if selectedProfile != "" {
 		configmanager.TriggerRetryLoop(selectedProfile, "initial partitioning")
+	} else {
+		configmanager.MarkReady()
 	}
 
 	// starting a seperate go routine for file watcher
 	go configmanager.StartFileWatcher(selectedProfile)
 
-	go configmanager.NodeLabelWatcher()
+	go configmanager.StartDriftReconciler()
+
+	if configmanager.IsStandaloneMode() {
+		go configmanager.StartProfileFileWatcher()
+	} else {
+		go configmanager.NodeLabelWatcher()
+	}
 
 	// Keep the program running
 	<-make(chan struct{})
@@ -79,12 +79,13 @@ mkdir -p $IMAGE_DIR
 IMAGE_URL="${DOCKER_REGISTRY}/device-config-manager:${VER}"
 
 echo $TOP_DIR
-rm -rf $TOP_DIR/docker/smilib $TOP_DIR/docker/device-config-manager
+rm -rf $TOP_DIR/docker/smilib $TOP_DIR/docker/device-config-manager $TOP_DIR/docker/dcmctl
 sleep 5
 
 # Always use RHEL9 OS for both openshift and K8s env
 cp -r $TOP_DIR/assets/amd_smi_lib/x86_64/RHEL9/lib $TOP_DIR/docker/smilib
 cp $TOP_DIR/bin/device-config-manager-$UBUNTU_VERSION $TOP_DIR/docker/device-config-manager
+cp $TOP_DIR/bin/dcmctl-$UBUNTU_VERSION $TOP_DIR/docker/dcmctl
 
 if [ $PUBLISH_IMAGE == 1 ]; then
     echo "publishing dcm image to $IMAGE_URL"
@@ -114,6 +115,6 @@ if [ "$SAVE_IMAGE" == 1 ]; then
     echo "Image ready in $IMAGE_DIR"
 fi
 
-rm -rf $TOP_DIR/docker/smilib $TOP_DIR/docker/device-config-manager
+rm -rf $TOP_DIR/docker/smilib $TOP_DIR/docker/device-config-manager $TOP_DIR/docker/dcmctl
 
 exit 0
@@ -33,6 +33,7 @@ package partition
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -153,6 +154,27 @@ type ProfileConfig struct {
 	ComputePartition string `protobuf:"bytes,1,opt,name=ComputePartition,proto3" json:"computePartition,omitempty"`
 	MemoryPartition  string `protobuf:"bytes,2,opt,name=MemoryPartition,proto3" json:"memoryPartition,omitempty"`
 	NumGPUsAssigned  uint32 `protobuf:"varint,3,opt,name=NumGPUsAssigned,proto3" json:"numGPUsAssigned,omitempty"`
+	// optional, applied with amdsmi_set_gpu_accelerator_partition_profile
+	// instead of the compute partition
+	AcceleratorProfile *AcceleratorProfile `protobuf:"bytes,4,opt,name=AcceleratorProfile,proto3" json:"acceleratorProfile,omitempty"`
+	// optional power cap in watts applied with amdsmi_set_power_cap, 0 keeps
+	// the current cap
+	PowerCapWatts uint32 `protobuf:"varint,5,opt,name=PowerCapWatts,proto3" json:"powerCapWatts,omitempty"`
+	// optional clock limits per clock domain, applied with
+	// amdsmi_set_gpu_clk_range or amdsmi_set_gpu_clk_limit
+	Clocks []*ClockRange `protobuf:"bytes,6,rep,name=Clocks,proto3" json:"clocks,omitempty"`
+	// optional performance level applied with amdsmi_set_gpu_perf_level,
+	// e.g. AUTO, MANUAL or DETERMINISM
+	PerfLevel string `protobuf:"bytes,7,opt,name=PerfLevel,proto3" json:"perfLevel,omitempty"`
+	// GFX clock soft maximum in MHz of the DETERMINISM performance level,
+	// applied with amdsmi_set_gpu_perf_determinism_mode
+	DeterminismClockMHz uint32 `protobuf:"varint,8,opt,name=DeterminismClockMHz,proto3" json:"determinismClockMHz,omitempty"`
+	// optional power profile preset applied with amdsmi_set_gpu_power_profile,
+	// e.g. COMPUTE or POWER_SAVING
+	PowerProfile string `protobuf:"bytes,9,opt,name=PowerProfile,proto3" json:"powerProfile,omitempty"`
+	// optional process isolation of the GPU partitions applied with
+	// amdsmi_set_gpu_process_isolation, left as is when unset
+	ProcessIsolation *bool `protobuf:"varint,10,opt,name=ProcessIsolation,proto3,oneof" json:"processIsolation,omitempty"`
 }
 
 func (x *ProfileConfig) Reset() {
@@ -208,6 +230,179 @@ func (x *ProfileConfig) GetNumGPUsAssigned() uint32 {
 	return 0
 }
 
+func (x *ProfileConfig) GetAcceleratorProfile() *AcceleratorProfile {
+	if x != nil {
+		return x.AcceleratorProfile
+	}
+	return nil
+}
+
+func (x *ProfileConfig) GetPowerCapWatts() uint32 {
+	if x != nil {
+		return x.PowerCapWatts
+	}
+	return 0
+}
+
+func (x *ProfileConfig) GetClocks() []*ClockRange {
+	if x != nil {
+		return x.Clocks
+	}
+	return nil
+}
+
+func (x *ProfileConfig) GetPerfLevel() string {
+	if x != nil {
+		return x.PerfLevel
+	}
+	return ""
+}
+
+func (x *ProfileConfig) GetDeterminismClockMHz() uint32 {
+	if x != nil {
+		return x.DeterminismClockMHz
+	}
+	return 0
+}
+
+func (x *ProfileConfig) GetPowerProfile() string {
+	if x != nil {
+		return x.PowerProfile
+	}
+	return ""
+}
+
+func (x *ProfileConfig) GetProcessIsolation() bool {
+	if x != nil && x.ProcessIsolation != nil {
+		return *x.ProcessIsolation
+	}
+	return false
+}
+
+// clock limits of a clock domain
+type ClockRange struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// clock domain, GFX or MEM
+	Type string `protobuf:"bytes,1,opt,name=Type,proto3" json:"type,omitempty"`
+	// bounds in MHz, 0 keeps the current bound
+	MinMHz uint32 `protobuf:"varint,2,opt,name=MinMHz,proto3" json:"minMHz,omitempty"`
+	MaxMHz uint32 `protobuf:"varint,3,opt,name=MaxMHz,proto3" json:"maxMHz,omitempty"`
+}
+
+func (x *ClockRange) Reset() {
+	*x = ClockRange{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ClockRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClockRange) ProtoMessage() {}
+
+func (x *ClockRange) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClockRange.ProtoReflect.Descriptor instead.
+func (*ClockRange) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ClockRange) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ClockRange) GetMinMHz() uint32 {
+	if x != nil {
+		return x.MinMHz
+	}
+	return 0
+}
+
+func (x *ClockRange) GetMaxMHz() uint32 {
+	if x != nil {
+		return x.MaxMHz
+	}
+	return 0
+}
+
+// accelerator partition profile, see amdsmi_accelerator_partition_profile_t
+type AcceleratorProfile struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// index of the profile in the accelerator partition profile config
+	Index uint32 `protobuf:"varint,1,opt,name=Index,proto3" json:"index,omitempty"`
+	// profile type, e.g. CPX, looked up instead of the index when set
+	Type string `protobuf:"bytes,2,opt,name=Type,proto3" json:"type,omitempty"`
+}
+
+func (x *AcceleratorProfile) Reset() {
+	*x = AcceleratorProfile{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AcceleratorProfile) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AcceleratorProfile) ProtoMessage() {}
+
+func (x *AcceleratorProfile) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AcceleratorProfile.ProtoReflect.Descriptor instead.
+func (*AcceleratorProfile) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *AcceleratorProfile) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *AcceleratorProfile) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
 type SkippedGPUs struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -219,7 +414,7 @@ type SkippedGPUs struct {
 func (x *SkippedGPUs) Reset() {
 	*x = SkippedGPUs{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_partition_proto_msgTypes[1]
+		mi := &file_partition_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -232,7 +427,7 @@ func (x *SkippedGPUs) String() string {
 func (*SkippedGPUs) ProtoMessage() {}
 
 func (x *SkippedGPUs) ProtoReflect() protoreflect.Message {
-	mi := &file_partition_proto_msgTypes[1]
+	mi := &file_partition_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -245,7 +440,7 @@ func (x *SkippedGPUs) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SkippedGPUs.ProtoReflect.Descriptor instead.
 func (*SkippedGPUs) Descriptor() ([]byte, []int) {
-	return file_partition_proto_rawDescGZIP(), []int{1}
+	return file_partition_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *SkippedGPUs) GetId() []uint32 {
@@ -267,7 +462,7 @@ type GPUConfigProfile struct {
 func (x *GPUConfigProfile) Reset() {
 	*x = GPUConfigProfile{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_partition_proto_msgTypes[2]
+		mi := &file_partition_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -280,7 +475,7 @@ func (x *GPUConfigProfile) String() string {
 func (*GPUConfigProfile) ProtoMessage() {}
 
 func (x *GPUConfigProfile) ProtoReflect() protoreflect.Message {
-	mi := &file_partition_proto_msgTypes[2]
+	mi := &file_partition_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -293,7 +488,7 @@ func (x *GPUConfigProfile) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GPUConfigProfile.ProtoReflect.Descriptor instead.
 func (*GPUConfigProfile) Descriptor() ([]byte, []int) {
-	return file_partition_proto_rawDescGZIP(), []int{2}
+	return file_partition_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GPUConfigProfile) GetFilters() *SkippedGPUs {
@@ -323,7 +518,7 @@ type GPUConfigProfiles struct {
 func (x *GPUConfigProfiles) Reset() {
 	*x = GPUConfigProfiles{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_partition_proto_msgTypes[3]
+		mi := &file_partition_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -336,7 +531,7 @@ func (x *GPUConfigProfiles) String() string {
 func (*GPUConfigProfiles) ProtoMessage() {}
 
 func (x *GPUConfigProfiles) ProtoReflect() protoreflect.Message {
-	mi := &file_partition_proto_msgTypes[3]
+	mi := &file_partition_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -349,7 +544,7 @@ func (x *GPUConfigProfiles) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GPUConfigProfiles.ProtoReflect.Descriptor instead.
 func (*GPUConfigProfiles) Descriptor() ([]byte, []int) {
-	return file_partition_proto_rawDescGZIP(), []int{3}
+	return file_partition_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GPUConfigProfiles) GetProfilesList() map[string]*GPUConfigProfile {
@@ -371,7 +566,7 @@ type GPUServiceList struct {
 func (x *GPUServiceList) Reset() {
 	*x = GPUServiceList{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_partition_proto_msgTypes[4]
+		mi := &file_partition_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -384,7 +579,7 @@ func (x *GPUServiceList) String() string {
 func (*GPUServiceList) ProtoMessage() {}
 
 func (x *GPUServiceList) ProtoReflect() protoreflect.Message {
-	mi := &file_partition_proto_msgTypes[4]
+	mi := &file_partition_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -397,7 +592,7 @@ func (x *GPUServiceList) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GPUServiceList.ProtoReflect.Descriptor instead.
 func (*GPUServiceList) Descriptor() ([]byte, []int) {
-	return file_partition_proto_rawDescGZIP(), []int{4}
+	return file_partition_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *GPUServiceList) GetNames() []string {
@@ -419,7 +614,7 @@ type GPUClientSystemdServices struct {
 func (x *GPUClientSystemdServices) Reset() {
 	*x = GPUClientSystemdServices{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_partition_proto_msgTypes[5]
+		mi := &file_partition_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -432,7 +627,7 @@ func (x *GPUClientSystemdServices) String() string {
 func (*GPUClientSystemdServices) ProtoMessage() {}
 
 func (x *GPUClientSystemdServices) ProtoReflect() protoreflect.Message {
-	mi := &file_partition_proto_msgTypes[5]
+	mi := &file_partition_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -445,7 +640,7 @@ func (x *GPUClientSystemdServices) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GPUClientSystemdServices.ProtoReflect.Descriptor instead.
 func (*GPUClientSystemdServices) Descriptor() ([]byte, []int) {
-	return file_partition_proto_rawDescGZIP(), []int{5}
+	return file_partition_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *GPUClientSystemdServices) GetList() *GPUServiceList {
@@ -455,169 +650,967 @@ func (x *GPUClientSystemdServices) GetList() *GPUServiceList {
 	return nil
 }
 
-var File_partition_proto protoreflect.FileDescriptor
+type GetStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
 
-var file_partition_proto_rawDesc = []byte{
-	0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x8f, 0x01, 0x0a,
-	0x0d, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2a,
-	0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
-	0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74,
-	0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x0f, 0x4d, 0x65,
-	0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0f, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x72, 0x74, 0x69,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x0f, 0x4e, 0x75, 0x6d, 0x47, 0x50, 0x55, 0x73, 0x41,
-	0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f, 0x4e,
-	0x75, 0x6d, 0x47, 0x50, 0x55, 0x73, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x22, 0x1d,
-	0x0a, 0x0b, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x47, 0x50, 0x55, 0x73, 0x12, 0x0e, 0x0a,
-	0x02, 0x49, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x64, 0x22, 0x7a, 0x0a,
-	0x10, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c,
-	0x65, 0x12, 0x30, 0x0a, 0x07, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01,
-	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x53,
-	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x47, 0x50, 0x55, 0x73, 0x52, 0x07, 0x46, 0x69, 0x6c, 0x74,
-	0x65, 0x72, 0x73, 0x12, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x18,
-	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
-	0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52,
-	0x08, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0xc5, 0x01, 0x0a, 0x11, 0x47, 0x50,
-	0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12,
-	0x52, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c, 0x69, 0x73, 0x74, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
-	0x6e, 0x2e, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69,
-	0x6c, 0x65, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c, 0x69, 0x73, 0x74,
-	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c,
-	0x69, 0x73, 0x74, 0x1a, 0x5c, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c,
-	0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76, 0x61,
-	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x61, 0x72, 0x74,
-	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50,
-	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38,
-	0x01, 0x22, 0x26, 0x0a, 0x0e, 0x47, 0x50, 0x55, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4c,
-	0x69, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03,
-	0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x49, 0x0a, 0x18, 0x47, 0x50, 0x55,
-	0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x53, 0x65, 0x72,
-	0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
-	0x47, 0x50, 0x55, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x04,
-	0x6c, 0x69, 0x73, 0x74, 0x2a, 0xa9, 0x01, 0x0a, 0x17, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6d, 0x70,
-	0x75, 0x74, 0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65,
-	0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f,
-	0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53,
-	0x50, 0x58, 0x10, 0x00, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d, 0x50,
-	0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49,
-	0x4f, 0x4e, 0x5f, 0x44, 0x50, 0x58, 0x10, 0x01, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f,
-	0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52,
-	0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x51, 0x50, 0x58, 0x10, 0x02, 0x12, 0x22, 0x0a, 0x1e,
-	0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45,
-	0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x50, 0x58, 0x10, 0x03,
-	0x2a, 0x84, 0x01, 0x0a, 0x16, 0x47, 0x50, 0x55, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61,
-	0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x1e, 0x47,
-	0x50, 0x55, 0x5f, 0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50,
-	0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x50, 0x53, 0x31, 0x10, 0x00, 0x12,
-	0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x54, 0x59,
-	0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x50, 0x53,
-	0x34, 0x10, 0x01, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x4d, 0x45, 0x4d, 0x4f, 0x52,
-	0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e,
-	0x5f, 0x4e, 0x50, 0x53, 0x32, 0x10, 0x02, 0x42, 0x0f, 0x5a, 0x0d, 0x67, 0x65, 0x6e, 0x2f, 0x70,
-	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *GetStatusRequest) Reset() {
+	*x = GetStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
 
-var (
-	file_partition_proto_rawDescOnce sync.Once
-	file_partition_proto_rawDescData = file_partition_proto_rawDesc
-)
+func (x *GetStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
 
-func file_partition_proto_rawDescGZIP() []byte {
-	file_partition_proto_rawDescOnce.Do(func() {
-		file_partition_proto_rawDescData = protoimpl.X.CompressGZIP(file_partition_proto_rawDescData)
-	})
-	return file_partition_proto_rawDescData
+func (*GetStatusRequest) ProtoMessage() {}
+
+func (x *GetStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
 }
 
-var file_partition_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_partition_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
-var file_partition_proto_goTypes = []any{
-	(GPUComputePartitionType)(0),     // 0: partition.GPUComputePartitionType
-	(GPUMemoryPartitionType)(0),      // 1: partition.GPUMemoryPartitionType
-	(*ProfileConfig)(nil),            // 2: partition.ProfileConfig
-	(*SkippedGPUs)(nil),              // 3: partition.SkippedGPUs
-	(*GPUConfigProfile)(nil),         // 4: partition.GPUConfigProfile
-	(*GPUConfigProfiles)(nil),        // 5: partition.GPUConfigProfiles
-	(*GPUServiceList)(nil),           // 6: partition.GPUServiceList
-	(*GPUClientSystemdServices)(nil), // 7: partition.GPUClientSystemdServices
-	nil,                              // 8: partition.GPUConfigProfiles.ProfilesListEntry
+// Deprecated: Use GetStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetStatusRequest) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{8}
 }
-var file_partition_proto_depIdxs = []int32{
-	3, // 0: partition.GPUConfigProfile.Filters:type_name -> partition.SkippedGPUs
-	2, // 1: partition.GPUConfigProfile.Profiles:type_name -> partition.ProfileConfig
-	8, // 2: partition.GPUConfigProfiles.ProfilesList:type_name -> partition.GPUConfigProfiles.ProfilesListEntry
-	6, // 3: partition.GPUClientSystemdServices.list:type_name -> partition.GPUServiceList
-	4, // 4: partition.GPUConfigProfiles.ProfilesListEntry.value:type_name -> partition.GPUConfigProfile
-	5, // [5:5] is the sub-list for method output_type
-	5, // [5:5] is the sub-list for method input_type
-	5, // [5:5] is the sub-list for extension type_name
-	5, // [5:5] is the sub-list for extension extendee
-	0, // [0:5] is the sub-list for field type_name
+
+// partition result of a GPU
+type GPUPartitionStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GpuID         uint32 `protobuf:"varint,1,opt,name=GpuID,proto3" json:"GpuID,omitempty"`
+	PartitionType string `protobuf:"bytes,2,opt,name=PartitionType,proto3" json:"PartitionType,omitempty"`
+	Status        string `protobuf:"bytes,3,opt,name=Status,proto3" json:"Status,omitempty"`
+	Message       string `protobuf:"bytes,4,opt,name=Message,proto3" json:"Message,omitempty"`
 }
 
-func init() { file_partition_proto_init() }
-func file_partition_proto_init() {
-	if File_partition_proto != nil {
-		return
+func (x *GPUPartitionStatus) Reset() {
+	*x = GPUPartitionStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
 	}
-	if !protoimpl.UnsafeEnabled {
-		file_partition_proto_msgTypes[0].Exporter = func(v any, i int) any {
-			switch v := v.(*ProfileConfig); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_partition_proto_msgTypes[1].Exporter = func(v any, i int) any {
-			switch v := v.(*SkippedGPUs); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_partition_proto_msgTypes[2].Exporter = func(v any, i int) any {
-			switch v := v.(*GPUConfigProfile); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_partition_proto_msgTypes[3].Exporter = func(v any, i int) any {
-			switch v := v.(*GPUConfigProfiles); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+}
+
+func (x *GPUPartitionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUPartitionStatus) ProtoMessage() {}
+
+func (x *GPUPartitionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_partition_proto_msgTypes[4].Exporter = func(v any, i int) any {
-			switch v := v.(*GPUServiceList); i {
-			case 0:
-				return &v.state
-			case 1:
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUPartitionStatus.ProtoReflect.Descriptor instead.
+func (*GPUPartitionStatus) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GPUPartitionStatus) GetGpuID() uint32 {
+	if x != nil {
+		return x.GpuID
+	}
+	return 0
+}
+
+func (x *GPUPartitionStatus) GetPartitionType() string {
+	if x != nil {
+		return x.PartitionType
+	}
+	return ""
+}
+
+func (x *GPUPartitionStatus) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *GPUPartitionStatus) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type PartitionStatus struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	SelectedProfile string                `protobuf:"bytes,1,opt,name=SelectedProfile,proto3" json:"SelectedProfile,omitempty"`
+	FinalStatus     string                `protobuf:"bytes,2,opt,name=FinalStatus,proto3" json:"FinalStatus,omitempty"`
+	Reason          string                `protobuf:"bytes,3,opt,name=Reason,proto3" json:"Reason,omitempty"`
+	GPUStatus       []*GPUPartitionStatus `protobuf:"bytes,4,rep,name=GPUStatus,proto3" json:"GPUStatus,omitempty"`
+	// value of the profile state node label, e.g. success or failure
+	ProfileState string `protobuf:"bytes,5,opt,name=ProfileState,proto3" json:"ProfileState,omitempty"`
+}
+
+func (x *PartitionStatus) Reset() {
+	*x = PartitionStatus{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartitionStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionStatus) ProtoMessage() {}
+
+func (x *PartitionStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionStatus.ProtoReflect.Descriptor instead.
+func (*PartitionStatus) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PartitionStatus) GetSelectedProfile() string {
+	if x != nil {
+		return x.SelectedProfile
+	}
+	return ""
+}
+
+func (x *PartitionStatus) GetFinalStatus() string {
+	if x != nil {
+		return x.FinalStatus
+	}
+	return ""
+}
+
+func (x *PartitionStatus) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PartitionStatus) GetGPUStatus() []*GPUPartitionStatus {
+	if x != nil {
+		return x.GPUStatus
+	}
+	return nil
+}
+
+func (x *PartitionStatus) GetProfileState() string {
+	if x != nil {
+		return x.ProfileState
+	}
+	return ""
+}
+
+type ListProfilesRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ListProfilesRequest) Reset() {
+	*x = ListProfilesRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListProfilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilesRequest) ProtoMessage() {}
+
+func (x *ListProfilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilesRequest.ProtoReflect.Descriptor instead.
+func (*ListProfilesRequest) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{11}
+}
+
+type ListProfilesResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// sorted profile names
+	Names  []string           `protobuf:"bytes,1,rep,name=Names,proto3" json:"Names,omitempty"`
+	Config *GPUConfigProfiles `protobuf:"bytes,2,opt,name=Config,proto3" json:"Config,omitempty"`
+}
+
+func (x *ListProfilesResponse) Reset() {
+	*x = ListProfilesResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ListProfilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProfilesResponse) ProtoMessage() {}
+
+func (x *ListProfilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProfilesResponse.ProtoReflect.Descriptor instead.
+func (*ListProfilesResponse) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListProfilesResponse) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *ListProfilesResponse) GetConfig() *GPUConfigProfiles {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type ApplyRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Profile string `protobuf:"bytes,1,opt,name=Profile,proto3" json:"Profile,omitempty"`
+	// only plan the changes, the GPUs are left as is
+	DryRun bool `protobuf:"varint,2,opt,name=DryRun,proto3" json:"DryRun,omitempty"`
+}
+
+func (x *ApplyRequest) Reset() {
+	*x = ApplyRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRequest) ProtoMessage() {}
+
+func (x *ApplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ApplyRequest) GetProfile() string {
+	if x != nil {
+		return x.Profile
+	}
+	return ""
+}
+
+func (x *ApplyRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+// change a profile makes on a GPU
+type GPUPlan struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	GpuID          uint32 `protobuf:"varint,1,opt,name=GpuID,proto3" json:"GpuID,omitempty"`
+	CurrentCompute string `protobuf:"bytes,2,opt,name=CurrentCompute,proto3" json:"CurrentCompute,omitempty"`
+	CurrentMemory  string `protobuf:"bytes,3,opt,name=CurrentMemory,proto3" json:"CurrentMemory,omitempty"`
+	TargetCompute  string `protobuf:"bytes,4,opt,name=TargetCompute,proto3" json:"TargetCompute,omitempty"`
+	TargetMemory   string `protobuf:"bytes,5,opt,name=TargetMemory,proto3" json:"TargetMemory,omitempty"`
+	ComputeChange  bool   `protobuf:"varint,6,opt,name=ComputeChange,proto3" json:"ComputeChange,omitempty"`
+	MemoryChange   bool   `protobuf:"varint,7,opt,name=MemoryChange,proto3" json:"MemoryChange,omitempty"`
+	// a memory partition change reloads the amdgpu driver
+	DriverReloadNeeded bool `protobuf:"varint,8,opt,name=DriverReloadNeeded,proto3" json:"DriverReloadNeeded,omitempty"`
+	// GPU settings such as the power cap change
+	SettingsChange bool `protobuf:"varint,9,opt,name=SettingsChange,proto3" json:"SettingsChange,omitempty"`
+}
+
+func (x *GPUPlan) Reset() {
+	*x = GPUPlan{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GPUPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GPUPlan) ProtoMessage() {}
+
+func (x *GPUPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GPUPlan.ProtoReflect.Descriptor instead.
+func (*GPUPlan) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GPUPlan) GetGpuID() uint32 {
+	if x != nil {
+		return x.GpuID
+	}
+	return 0
+}
+
+func (x *GPUPlan) GetCurrentCompute() string {
+	if x != nil {
+		return x.CurrentCompute
+	}
+	return ""
+}
+
+func (x *GPUPlan) GetCurrentMemory() string {
+	if x != nil {
+		return x.CurrentMemory
+	}
+	return ""
+}
+
+func (x *GPUPlan) GetTargetCompute() string {
+	if x != nil {
+		return x.TargetCompute
+	}
+	return ""
+}
+
+func (x *GPUPlan) GetTargetMemory() string {
+	if x != nil {
+		return x.TargetMemory
+	}
+	return ""
+}
+
+func (x *GPUPlan) GetComputeChange() bool {
+	if x != nil {
+		return x.ComputeChange
+	}
+	return false
+}
+
+func (x *GPUPlan) GetMemoryChange() bool {
+	if x != nil {
+		return x.MemoryChange
+	}
+	return false
+}
+
+func (x *GPUPlan) GetDriverReloadNeeded() bool {
+	if x != nil {
+		return x.DriverReloadNeeded
+	}
+	return false
+}
+
+func (x *GPUPlan) GetSettingsChange() bool {
+	if x != nil {
+		return x.SettingsChange
+	}
+	return false
+}
+
+type ApplyResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Plan []*GPUPlan `protobuf:"bytes,1,rep,name=Plan,proto3" json:"Plan,omitempty"`
+	// systemd services stopped while the GPUs are partitioned
+	ServicesToStop []string `protobuf:"bytes,2,rep,name=ServicesToStop,proto3" json:"ServicesToStop,omitempty"`
+	// set when partitioning was started, follow it with WatchEvents
+	Started bool `protobuf:"varint,3,opt,name=Started,proto3" json:"Started,omitempty"`
+}
+
+func (x *ApplyResponse) Reset() {
+	*x = ApplyResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApplyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyResponse) ProtoMessage() {}
+
+func (x *ApplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyResponse.ProtoReflect.Descriptor instead.
+func (*ApplyResponse) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ApplyResponse) GetPlan() []*GPUPlan {
+	if x != nil {
+		return x.Plan
+	}
+	return nil
+}
+
+func (x *ApplyResponse) GetServicesToStop() []string {
+	if x != nil {
+		return x.ServicesToStop
+	}
+	return nil
+}
+
+func (x *ApplyResponse) GetStarted() bool {
+	if x != nil {
+		return x.Started
+	}
+	return false
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{16}
+}
+
+// partitioning progress, either the status of a GPU or an event of the node
+type PartitionEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Time            *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=Time,proto3" json:"Time,omitempty"`
+	SelectedProfile string                 `protobuf:"bytes,2,opt,name=SelectedProfile,proto3" json:"SelectedProfile,omitempty"`
+	// set for per GPU progress
+	GPU *GPUPartitionStatus `protobuf:"bytes,3,opt,name=GPU,proto3" json:"GPU,omitempty"`
+	// event name, e.g. SuccessfullyPartitioned, set for node events
+	Event  string `protobuf:"bytes,4,opt,name=Event,proto3" json:"Event,omitempty"`
+	Reason string `protobuf:"bytes,5,opt,name=Reason,proto3" json:"Reason,omitempty"`
+}
+
+func (x *PartitionEvent) Reset() {
+	*x = PartitionEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_partition_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PartitionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PartitionEvent) ProtoMessage() {}
+
+func (x *PartitionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_partition_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PartitionEvent.ProtoReflect.Descriptor instead.
+func (*PartitionEvent) Descriptor() ([]byte, []int) {
+	return file_partition_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *PartitionEvent) GetTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Time
+	}
+	return nil
+}
+
+func (x *PartitionEvent) GetSelectedProfile() string {
+	if x != nil {
+		return x.SelectedProfile
+	}
+	return ""
+}
+
+func (x *PartitionEvent) GetGPU() *GPUPartitionStatus {
+	if x != nil {
+		return x.GPU
+	}
+	return nil
+}
+
+func (x *PartitionEvent) GetEvent() string {
+	if x != nil {
+		return x.Event
+	}
+	return ""
+}
+
+func (x *PartitionEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_partition_proto protoreflect.FileDescriptor
+
+var file_partition_proto_rawDesc = []byte{
+	0x0a, 0x0f, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x09, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x1f, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69,
+	0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xed, 0x03,
+	0x0a, 0x0d, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x2a, 0x0a, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x10, 0x43, 0x6f, 0x6d, 0x70, 0x75,
+	0x74, 0x65, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x0f, 0x4d,
+	0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x28, 0x0a, 0x0f, 0x4e, 0x75, 0x6d, 0x47, 0x50, 0x55, 0x73,
+	0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0f,
+	0x4e, 0x75, 0x6d, 0x47, 0x50, 0x55, 0x73, 0x41, 0x73, 0x73, 0x69, 0x67, 0x6e, 0x65, 0x64, 0x12,
+	0x4d, 0x0a, 0x12, 0x41, 0x63, 0x63, 0x65, 0x6c, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x63, 0x63, 0x65, 0x6c, 0x65, 0x72, 0x61,
+	0x74, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x12, 0x41, 0x63, 0x63, 0x65,
+	0x6c, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x24,
+	0x0a, 0x0d, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x43, 0x61, 0x70, 0x57, 0x61, 0x74, 0x74, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0d, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x43, 0x61, 0x70, 0x57,
+	0x61, 0x74, 0x74, 0x73, 0x12, 0x2d, 0x0a, 0x06, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x06,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x06, 0x43, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x50, 0x65, 0x72, 0x66, 0x4c, 0x65, 0x76, 0x65, 0x6c,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x50, 0x65, 0x72, 0x66, 0x4c, 0x65, 0x76, 0x65,
+	0x6c, 0x12, 0x30, 0x0a, 0x13, 0x44, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x69, 0x73, 0x6d,
+	0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x4d, 0x48, 0x7a, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x13,
+	0x44, 0x65, 0x74, 0x65, 0x72, 0x6d, 0x69, 0x6e, 0x69, 0x73, 0x6d, 0x43, 0x6c, 0x6f, 0x63, 0x6b,
+	0x4d, 0x48, 0x7a, 0x12, 0x22, 0x0a, 0x0c, 0x50, 0x6f, 0x77, 0x65, 0x72, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x50, 0x6f, 0x77, 0x65, 0x72,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x2f, 0x0a, 0x10, 0x50, 0x72, 0x6f, 0x63, 0x65,
+	0x73, 0x73, 0x49, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28,
+	0x08, 0x48, 0x00, 0x52, 0x10, 0x50, 0x72, 0x6f, 0x63, 0x65, 0x73, 0x73, 0x49, 0x73, 0x6f, 0x6c,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x42, 0x13, 0x0a, 0x11, 0x5f, 0x50, 0x72, 0x6f,
+	0x63, 0x65, 0x73, 0x73, 0x49, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x50, 0x0a,
+	0x0a, 0x43, 0x6c, 0x6f, 0x63, 0x6b, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x12,
+	0x16, 0x0a, 0x06, 0x4d, 0x69, 0x6e, 0x4d, 0x48, 0x7a, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x4d, 0x69, 0x6e, 0x4d, 0x48, 0x7a, 0x12, 0x16, 0x0a, 0x06, 0x4d, 0x61, 0x78, 0x4d, 0x48,
+	0x7a, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x4d, 0x61, 0x78, 0x4d, 0x48, 0x7a, 0x22,
+	0x3e, 0x0a, 0x12, 0x41, 0x63, 0x63, 0x65, 0x6c, 0x65, 0x72, 0x61, 0x74, 0x6f, 0x72, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x49, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x54,
+	0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x54, 0x79, 0x70, 0x65, 0x22,
+	0x1d, 0x0a, 0x0b, 0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x47, 0x50, 0x55, 0x73, 0x12, 0x0e,
+	0x0a, 0x02, 0x49, 0x64, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x02, 0x49, 0x64, 0x22, 0x7a,
+	0x0a, 0x10, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x12, 0x30, 0x0a, 0x07, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e,
+	0x53, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x47, 0x50, 0x55, 0x73, 0x52, 0x07, 0x46, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x73, 0x12, 0x34, 0x0a, 0x08, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x52, 0x08, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x22, 0xc5, 0x01, 0x0a, 0x11, 0x47,
+	0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x12, 0x52, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c, 0x69, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x50, 0x72, 0x6f, 0x66,
+	0x69, 0x6c, 0x65, 0x73, 0x2e, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x4c, 0x69, 0x73,
+	0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x4c, 0x69, 0x73, 0x74, 0x1a, 0x5c, 0x0a, 0x11, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73,
+	0x4c, 0x69, 0x73, 0x74, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0x26, 0x0a, 0x0e, 0x47, 0x50, 0x55, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
+	0x4c, 0x69, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x05, 0x6e, 0x61, 0x6d, 0x65, 0x73, 0x22, 0x49, 0x0a, 0x18, 0x47, 0x50,
+	0x55, 0x43, 0x6c, 0x69, 0x65, 0x6e, 0x74, 0x53, 0x79, 0x73, 0x74, 0x65, 0x6d, 0x64, 0x53, 0x65,
+	0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x04, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x2e, 0x47, 0x50, 0x55, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x73, 0x74, 0x52,
+	0x04, 0x6c, 0x69, 0x73, 0x74, 0x22, 0x12, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x82, 0x01, 0x0a, 0x12, 0x47, 0x50,
+	0x55, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x12, 0x14, 0x0a, 0x05, 0x47, 0x70, 0x75, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x47, 0x70, 0x75, 0x49, 0x44, 0x12, 0x24, 0x0a, 0x0d, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74,
+	0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x53, 0x74,
+	0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x4d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0xd6,
+	0x01, 0x0a, 0x0f, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x28, 0x0a, 0x0f, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x50, 0x72,
+	0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x53, 0x65, 0x6c,
+	0x65, 0x63, 0x74, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x20, 0x0a, 0x0b,
+	0x46, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x46, 0x69, 0x6e, 0x61, 0x6c, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x16,
+	0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06,
+	0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x3b, 0x0a, 0x09, 0x47, 0x50, 0x55, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69,
+	0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x09, 0x47, 0x50, 0x55, 0x53, 0x74, 0x61,
+	0x74, 0x75, 0x73, 0x12, 0x22, 0x0a, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x53, 0x74,
+	0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x50, 0x72, 0x6f, 0x66, 0x69,
+	0x6c, 0x65, 0x53, 0x74, 0x61, 0x74, 0x65, 0x22, 0x15, 0x0a, 0x13, 0x4c, 0x69, 0x73, 0x74, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x62,
+	0x0a, 0x14, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x4e, 0x61, 0x6d, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x06,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x70,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x06, 0x43, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x22, 0x40, 0x0a, 0x0c, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x18, 0x0a, 0x07, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x16, 0x0a, 0x06,
+	0x44, 0x72, 0x79, 0x52, 0x75, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x44, 0x72,
+	0x79, 0x52, 0x75, 0x6e, 0x22, 0xd9, 0x02, 0x0a, 0x07, 0x47, 0x50, 0x55, 0x50, 0x6c, 0x61, 0x6e,
+	0x12, 0x14, 0x0a, 0x05, 0x47, 0x70, 0x75, 0x49, 0x44, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x05, 0x47, 0x70, 0x75, 0x49, 0x44, 0x12, 0x26, 0x0a, 0x0e, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x74, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e,
+	0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x12, 0x24,
+	0x0a, 0x0d, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x43, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x74, 0x4d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x12, 0x24, 0x0a, 0x0d, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x43, 0x6f,
+	0x6d, 0x70, 0x75, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x54, 0x61, 0x72,
+	0x67, 0x65, 0x74, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x54, 0x61,
+	0x72, 0x67, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0c, 0x54, 0x61, 0x72, 0x67, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x24,
+	0x0a, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x12, 0x22, 0x0a, 0x0c, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x43, 0x68,
+	0x61, 0x6e, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0c, 0x4d, 0x65, 0x6d, 0x6f,
+	0x72, 0x79, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x12, 0x2e, 0x0a, 0x12, 0x44, 0x72, 0x69, 0x76,
+	0x65, 0x72, 0x52, 0x65, 0x6c, 0x6f, 0x61, 0x64, 0x4e, 0x65, 0x65, 0x64, 0x65, 0x64, 0x18, 0x08,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x44, 0x72, 0x69, 0x76, 0x65, 0x72, 0x52, 0x65, 0x6c, 0x6f,
+	0x61, 0x64, 0x4e, 0x65, 0x65, 0x64, 0x65, 0x64, 0x12, 0x26, 0x0a, 0x0e, 0x53, 0x65, 0x74, 0x74,
+	0x69, 0x6e, 0x67, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65, 0x18, 0x09, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0e, 0x53, 0x65, 0x74, 0x74, 0x69, 0x6e, 0x67, 0x73, 0x43, 0x68, 0x61, 0x6e, 0x67, 0x65,
+	0x22, 0x79, 0x0a, 0x0d, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x26, 0x0a, 0x04, 0x50, 0x6c, 0x61, 0x6e, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x12, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x50,
+	0x6c, 0x61, 0x6e, 0x52, 0x04, 0x50, 0x6c, 0x61, 0x6e, 0x12, 0x26, 0x0a, 0x0e, 0x53, 0x65, 0x72,
+	0x76, 0x69, 0x63, 0x65, 0x73, 0x54, 0x6f, 0x53, 0x74, 0x6f, 0x70, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x54, 0x6f, 0x53, 0x74, 0x6f,
+	0x70, 0x12, 0x18, 0x0a, 0x07, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x53, 0x74, 0x61, 0x72, 0x74, 0x65, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x22, 0xc9, 0x01, 0x0a, 0x0e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x12, 0x2e, 0x0a, 0x04, 0x54, 0x69, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x04,
+	0x54, 0x69, 0x6d, 0x65, 0x12, 0x28, 0x0a, 0x0f, 0x53, 0x65, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64,
+	0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x53,
+	0x65, 0x6c, 0x65, 0x63, 0x74, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x12, 0x2f,
+	0x0a, 0x03, 0x47, 0x50, 0x55, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x47, 0x50, 0x55, 0x50, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x03, 0x47, 0x50, 0x55, 0x12,
+	0x14, 0x0a, 0x05, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x2a, 0xa9, 0x01,
+	0x0a, 0x17, 0x47, 0x50, 0x55, 0x43, 0x6f, 0x6d, 0x70, 0x75, 0x74, 0x65, 0x50, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x54, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55,
+	0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41,
+	0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x53, 0x50, 0x58, 0x10, 0x00, 0x12, 0x22, 0x0a,
+	0x1e, 0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50,
+	0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x44, 0x50, 0x58, 0x10,
+	0x01, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d, 0x50, 0x55, 0x54, 0x45,
+	0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f,
+	0x51, 0x50, 0x58, 0x10, 0x02, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x43, 0x4f, 0x4d,
+	0x50, 0x55, 0x54, 0x45, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54,
+	0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x50, 0x58, 0x10, 0x03, 0x2a, 0x84, 0x01, 0x0a, 0x16, 0x47, 0x50,
+	0x55, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f, 0x4d, 0x45, 0x4d, 0x4f,
+	0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f,
+	0x4e, 0x5f, 0x4e, 0x50, 0x53, 0x31, 0x10, 0x00, 0x12, 0x22, 0x0a, 0x1e, 0x47, 0x50, 0x55, 0x5f,
+	0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x50, 0x41, 0x52, 0x54,
+	0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x50, 0x53, 0x34, 0x10, 0x01, 0x12, 0x22, 0x0a, 0x1e,
+	0x47, 0x50, 0x55, 0x5f, 0x4d, 0x45, 0x4d, 0x4f, 0x52, 0x59, 0x5f, 0x54, 0x59, 0x50, 0x45, 0x5f,
+	0x50, 0x41, 0x52, 0x54, 0x49, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x4e, 0x50, 0x53, 0x32, 0x10, 0x02,
+	0x32, 0xb3, 0x02, 0x0a, 0x13, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x4d, 0x61, 0x6e, 0x61, 0x67, 0x65, 0x72, 0x12, 0x44, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1b, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f,
+	0x6e, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1a, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50,
+	0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x4f,
+	0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x50, 0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x12, 0x1e,
+	0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f,
+	0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x50,
+	0x72, 0x6f, 0x66, 0x69, 0x6c, 0x65, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x3a, 0x0a, 0x05, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x12, 0x17, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x70, 0x70, 0x6c, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x18, 0x2e, 0x70, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x41, 0x70,
+	0x70, 0x6c, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x49, 0x0a, 0x0b, 0x57,
+	0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x12, 0x1d, 0x2e, 0x70, 0x61, 0x72,
+	0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x57, 0x61, 0x74, 0x63, 0x68, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x19, 0x2e, 0x70, 0x61, 0x72, 0x74,
+	0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x50, 0x61, 0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x30, 0x01, 0x42, 0x0f, 0x5a, 0x0d, 0x67, 0x65, 0x6e, 0x2f, 0x70, 0x61,
+	0x72, 0x74, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_partition_proto_rawDescOnce sync.Once
+	file_partition_proto_rawDescData = file_partition_proto_rawDesc
+)
+
+func file_partition_proto_rawDescGZIP() []byte {
+	file_partition_proto_rawDescOnce.Do(func() {
+		file_partition_proto_rawDescData = protoimpl.X.CompressGZIP(file_partition_proto_rawDescData)
+	})
+	return file_partition_proto_rawDescData
+}
+
+var file_partition_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_partition_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_partition_proto_goTypes = []any{
+	(GPUComputePartitionType)(0),     // 0: partition.GPUComputePartitionType
+	(GPUMemoryPartitionType)(0),      // 1: partition.GPUMemoryPartitionType
+	(*ProfileConfig)(nil),            // 2: partition.ProfileConfig
+	(*ClockRange)(nil),               // 3: partition.ClockRange
+	(*AcceleratorProfile)(nil),       // 4: partition.AcceleratorProfile
+	(*SkippedGPUs)(nil),              // 5: partition.SkippedGPUs
+	(*GPUConfigProfile)(nil),         // 6: partition.GPUConfigProfile
+	(*GPUConfigProfiles)(nil),        // 7: partition.GPUConfigProfiles
+	(*GPUServiceList)(nil),           // 8: partition.GPUServiceList
+	(*GPUClientSystemdServices)(nil), // 9: partition.GPUClientSystemdServices
+	(*GetStatusRequest)(nil),         // 10: partition.GetStatusRequest
+	(*GPUPartitionStatus)(nil),       // 11: partition.GPUPartitionStatus
+	(*PartitionStatus)(nil),          // 12: partition.PartitionStatus
+	(*ListProfilesRequest)(nil),      // 13: partition.ListProfilesRequest
+	(*ListProfilesResponse)(nil),     // 14: partition.ListProfilesResponse
+	(*ApplyRequest)(nil),             // 15: partition.ApplyRequest
+	(*GPUPlan)(nil),                  // 16: partition.GPUPlan
+	(*ApplyResponse)(nil),            // 17: partition.ApplyResponse
+	(*WatchEventsRequest)(nil),       // 18: partition.WatchEventsRequest
+	(*PartitionEvent)(nil),           // 19: partition.PartitionEvent
+	nil,                              // 20: partition.GPUConfigProfiles.ProfilesListEntry
+	(*timestamppb.Timestamp)(nil),    // 21: google.protobuf.Timestamp
+}
+var file_partition_proto_depIdxs = []int32{
+	4,  // 0: partition.ProfileConfig.AcceleratorProfile:type_name -> partition.AcceleratorProfile
+	3,  // 1: partition.ProfileConfig.Clocks:type_name -> partition.ClockRange
+	5,  // 2: partition.GPUConfigProfile.Filters:type_name -> partition.SkippedGPUs
+	2,  // 3: partition.GPUConfigProfile.Profiles:type_name -> partition.ProfileConfig
+	20, // 4: partition.GPUConfigProfiles.ProfilesList:type_name -> partition.GPUConfigProfiles.ProfilesListEntry
+	8,  // 5: partition.GPUClientSystemdServices.list:type_name -> partition.GPUServiceList
+	11, // 6: partition.PartitionStatus.GPUStatus:type_name -> partition.GPUPartitionStatus
+	7,  // 7: partition.ListProfilesResponse.Config:type_name -> partition.GPUConfigProfiles
+	16, // 8: partition.ApplyResponse.Plan:type_name -> partition.GPUPlan
+	21, // 9: partition.PartitionEvent.Time:type_name -> google.protobuf.Timestamp
+	11, // 10: partition.PartitionEvent.GPU:type_name -> partition.GPUPartitionStatus
+	6,  // 11: partition.GPUConfigProfiles.ProfilesListEntry.value:type_name -> partition.GPUConfigProfile
+	10, // 12: partition.DeviceConfigManager.GetStatus:input_type -> partition.GetStatusRequest
+	13, // 13: partition.DeviceConfigManager.ListProfiles:input_type -> partition.ListProfilesRequest
+	15, // 14: partition.DeviceConfigManager.Apply:input_type -> partition.ApplyRequest
+	18, // 15: partition.DeviceConfigManager.WatchEvents:input_type -> partition.WatchEventsRequest
+	12, // 16: partition.DeviceConfigManager.GetStatus:output_type -> partition.PartitionStatus
+	14, // 17: partition.DeviceConfigManager.ListProfiles:output_type -> partition.ListProfilesResponse
+	17, // 18: partition.DeviceConfigManager.Apply:output_type -> partition.ApplyResponse
+	19, // 19: partition.DeviceConfigManager.WatchEvents:output_type -> partition.PartitionEvent
+	16, // [16:20] is the sub-list for method output_type
+	12, // [12:16] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_partition_proto_init() }
+func file_partition_proto_init() {
+	if File_partition_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_partition_proto_msgTypes[0].Exporter = func(v any, i int) any {
+			switch v := v.(*ProfileConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[1].Exporter = func(v any, i int) any {
+			switch v := v.(*ClockRange); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[2].Exporter = func(v any, i int) any {
+			switch v := v.(*AcceleratorProfile); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[3].Exporter = func(v any, i int) any {
+			switch v := v.(*SkippedGPUs); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[4].Exporter = func(v any, i int) any {
+			switch v := v.(*GPUConfigProfile); i {
+			case 0:
+				return &v.state
+			case 1:
 				return &v.sizeCache
 			case 2:
 				return &v.unknownFields
@@ -626,6 +1619,30 @@ func file_partition_proto_init() {
 			}
 		}
 		file_partition_proto_msgTypes[5].Exporter = func(v any, i int) any {
+			switch v := v.(*GPUConfigProfiles); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[6].Exporter = func(v any, i int) any {
+			switch v := v.(*GPUServiceList); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[7].Exporter = func(v any, i int) any {
 			switch v := v.(*GPUClientSystemdServices); i {
 			case 0:
 				return &v.state
@@ -637,16 +1654,137 @@ func file_partition_proto_init() {
 				return nil
 			}
 		}
+		file_partition_proto_msgTypes[8].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[9].Exporter = func(v any, i int) any {
+			switch v := v.(*GPUPartitionStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[10].Exporter = func(v any, i int) any {
+			switch v := v.(*PartitionStatus); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[11].Exporter = func(v any, i int) any {
+			switch v := v.(*ListProfilesRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[12].Exporter = func(v any, i int) any {
+			switch v := v.(*ListProfilesResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[13].Exporter = func(v any, i int) any {
+			switch v := v.(*ApplyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[14].Exporter = func(v any, i int) any {
+			switch v := v.(*GPUPlan); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[15].Exporter = func(v any, i int) any {
+			switch v := v.(*ApplyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*WatchEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_partition_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*PartitionEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
+	file_partition_proto_msgTypes[0].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_partition_proto_rawDesc,
 			NumEnums:      2,
-			NumMessages:   7,
+			NumMessages:   19,
 			NumExtensions: 0,
-			NumServices:   0,
+			NumServices:   1,
 		},
 		GoTypes:           file_partition_proto_goTypes,
 		DependencyIndexes: file_partition_proto_depIdxs,
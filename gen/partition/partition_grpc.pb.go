@@ -0,0 +1,279 @@
+//
+// Copyright(C) Advanced Micro Devices, Inc. All rights reserved.
+//
+// You may not use this software and documentation (if any) (collectively,
+// the "Materials") except in compliance with the terms and conditions of
+// the Software License Agreement included with the Materials or otherwise as
+// set forth in writing and signed by you and an authorized signatory of AMD.
+// If you do not have a copy of the Software License Agreement, contact your
+// AMD representative for a copy.
+//
+// You agree that you will not reverse engineer or decompile the Materials,
+// in whole or in part, except as allowed by applicable law.
+//
+// THE MATERIALS ARE DISTRIBUTED ON AN "AS IS" BASIS, WITHOUT WARRANTIES OR
+// REPRESENTATIONS OF ANY KIND, EITHER EXPRESS OR IMPLIED.
+//
+
+//----------------------------------------------------------------------------
+///
+/// \file
+/// AMD Configs Manager fields
+///
+//----------------------------------------------------------------------------
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.12.4
+// source: partition.proto
+
+package partition
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DeviceConfigManager_GetStatus_FullMethodName    = "/partition.DeviceConfigManager/GetStatus"
+	DeviceConfigManager_ListProfiles_FullMethodName = "/partition.DeviceConfigManager/ListProfiles"
+	DeviceConfigManager_Apply_FullMethodName        = "/partition.DeviceConfigManager/Apply"
+	DeviceConfigManager_WatchEvents_FullMethodName  = "/partition.DeviceConfigManager/WatchEvents"
+)
+
+// DeviceConfigManagerClient is the client API for DeviceConfigManager service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DeviceConfigManager is served on a unix socket of the node for local agents
+// that follow the partitioning without going through the k8s API
+type DeviceConfigManagerClient interface {
+	// result of the last partitioning
+	GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*PartitionStatus, error)
+	// profiles of the config file
+	ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error)
+	// select the profile and partition the GPUs with it, a dry run only plans
+	// the profile
+	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error)
+	// stream of the per GPU progress and the events of every partitioning
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PartitionEvent], error)
+}
+
+type deviceConfigManagerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDeviceConfigManagerClient(cc grpc.ClientConnInterface) DeviceConfigManagerClient {
+	return &deviceConfigManagerClient{cc}
+}
+
+func (c *deviceConfigManagerClient) GetStatus(ctx context.Context, in *GetStatusRequest, opts ...grpc.CallOption) (*PartitionStatus, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PartitionStatus)
+	err := c.cc.Invoke(ctx, DeviceConfigManager_GetStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceConfigManagerClient) ListProfiles(ctx context.Context, in *ListProfilesRequest, opts ...grpc.CallOption) (*ListProfilesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListProfilesResponse)
+	err := c.cc.Invoke(ctx, DeviceConfigManager_ListProfiles_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceConfigManagerClient) Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyResponse)
+	err := c.cc.Invoke(ctx, DeviceConfigManager_Apply_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *deviceConfigManagerClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PartitionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &DeviceConfigManager_ServiceDesc.Streams[0], DeviceConfigManager_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, PartitionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceConfigManager_WatchEventsClient = grpc.ServerStreamingClient[PartitionEvent]
+
+// DeviceConfigManagerServer is the server API for DeviceConfigManager service.
+// All implementations must embed UnimplementedDeviceConfigManagerServer
+// for forward compatibility.
+//
+// DeviceConfigManager is served on a unix socket of the node for local agents
+// that follow the partitioning without going through the k8s API
+type DeviceConfigManagerServer interface {
+	// result of the last partitioning
+	GetStatus(context.Context, *GetStatusRequest) (*PartitionStatus, error)
+	// profiles of the config file
+	ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error)
+	// select the profile and partition the GPUs with it, a dry run only plans
+	// the profile
+	Apply(context.Context, *ApplyRequest) (*ApplyResponse, error)
+	// stream of the per GPU progress and the events of every partitioning
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[PartitionEvent]) error
+	mustEmbedUnimplementedDeviceConfigManagerServer()
+}
+
+// UnimplementedDeviceConfigManagerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDeviceConfigManagerServer struct{}
+
+func (UnimplementedDeviceConfigManagerServer) GetStatus(context.Context, *GetStatusRequest) (*PartitionStatus, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedDeviceConfigManagerServer) ListProfiles(context.Context, *ListProfilesRequest) (*ListProfilesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProfiles not implemented")
+}
+func (UnimplementedDeviceConfigManagerServer) Apply(context.Context, *ApplyRequest) (*ApplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Apply not implemented")
+}
+func (UnimplementedDeviceConfigManagerServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[PartitionEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedDeviceConfigManagerServer) mustEmbedUnimplementedDeviceConfigManagerServer() {}
+func (UnimplementedDeviceConfigManagerServer) testEmbeddedByValue()                             {}
+
+// UnsafeDeviceConfigManagerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DeviceConfigManagerServer will
+// result in compilation errors.
+type UnsafeDeviceConfigManagerServer interface {
+	mustEmbedUnimplementedDeviceConfigManagerServer()
+}
+
+func RegisterDeviceConfigManagerServer(s grpc.ServiceRegistrar, srv DeviceConfigManagerServer) {
+	// If the following call pancis, it indicates UnimplementedDeviceConfigManagerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DeviceConfigManager_ServiceDesc, srv)
+}
+
+func _DeviceConfigManager_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceConfigManagerServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceConfigManager_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceConfigManagerServer).GetStatus(ctx, req.(*GetStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceConfigManager_ListProfiles_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProfilesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceConfigManagerServer).ListProfiles(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceConfigManager_ListProfiles_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceConfigManagerServer).ListProfiles(ctx, req.(*ListProfilesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceConfigManager_Apply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DeviceConfigManagerServer).Apply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DeviceConfigManager_Apply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DeviceConfigManagerServer).Apply(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DeviceConfigManager_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(DeviceConfigManagerServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, PartitionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type DeviceConfigManager_WatchEventsServer = grpc.ServerStreamingServer[PartitionEvent]
+
+// DeviceConfigManager_ServiceDesc is the grpc.ServiceDesc for DeviceConfigManager service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DeviceConfigManager_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "partition.DeviceConfigManager",
+	HandlerType: (*DeviceConfigManagerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _DeviceConfigManager_GetStatus_Handler,
+		},
+		{
+			MethodName: "ListProfiles",
+			Handler:    _DeviceConfigManager_ListProfiles_Handler,
+		},
+		{
+			MethodName: "Apply",
+			Handler:    _DeviceConfigManager_Apply_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _DeviceConfigManager_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "partition.proto",
+}
@@ -0,0 +1,615 @@
+//go:build amdsmi
+
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+/*
+#cgo CFLAGS: -I/device-config-manager/build/assets/amd_smi
+#cgo LDFLAGS: -L/device-config-manager/build/assets -lamd_smi -ldrm_amdgpu -ldrm
+#include "/device-config-manager/build/assets/amdsmi.h"
+*/
+import "C"
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	log_e "github.com/sirupsen/logrus"
+)
+
+// amdsmiBackend talks to the GPUs through libamd_smi
+type amdsmiBackend struct {
+	sync.Mutex
+	sockets []C.amdsmi_socket_handle
+}
+
+func newAMDSMIBackend() (GPUBackend, error) {
+	return &amdsmiBackend{}, nil
+}
+
+func convertComputePartitonType(partitionType string) C.amdsmi_compute_partition_type_t {
+	switch partitionType {
+	case "CPX":
+		return C.AMDSMI_COMPUTE_PARTITION_CPX
+	case "SPX":
+		return C.AMDSMI_COMPUTE_PARTITION_SPX
+	case "DPX":
+		return C.AMDSMI_COMPUTE_PARTITION_DPX
+	case "TPX":
+		return C.AMDSMI_COMPUTE_PARTITION_TPX
+	case "QPX":
+		return C.AMDSMI_COMPUTE_PARTITION_QPX
+	default:
+		log_e.Errorf("Unknown compute partition type: %s, using default type SPX", partitionType)
+		return C.AMDSMI_COMPUTE_PARTITION_SPX // default value
+	}
+}
+
+func convertMemoryPartitionType(memoryPartition string) C.amdsmi_memory_partition_type_t {
+	switch memoryPartition {
+	case "NPS1":
+		return C.AMDSMI_MEMORY_PARTITION_NPS1
+	case "NPS2":
+		return C.AMDSMI_MEMORY_PARTITION_NPS2
+	case "NPS4":
+		return C.AMDSMI_MEMORY_PARTITION_NPS4
+	default:
+		log_e.Errorf("Unknown memory partition type: %s, using default type NPS1", memoryPartition)
+		return C.AMDSMI_MEMORY_PARTITION_NPS1 // default value
+	}
+}
+
+var acceleratorPartitionNames = map[C.amdsmi_accelerator_partition_type_t]string{
+	C.AMDSMI_ACCELERATOR_PARTITION_SPX: "SPX",
+	C.AMDSMI_ACCELERATOR_PARTITION_DPX: "DPX",
+	C.AMDSMI_ACCELERATOR_PARTITION_TPX: "TPX",
+	C.AMDSMI_ACCELERATOR_PARTITION_QPX: "QPX",
+	C.AMDSMI_ACCELERATOR_PARTITION_CPX: "CPX",
+}
+
+var acceleratorResourceNames = map[C.amdsmi_accelerator_partition_resource_type_t]string{
+	C.AMDSMI_ACCELERATOR_XCC:     "XCC",
+	C.AMDSMI_ACCELERATOR_ENCODER: "ENCODER",
+	C.AMDSMI_ACCELERATOR_DECODER: "DECODER",
+	C.AMDSMI_ACCELERATOR_DMA:     "DMA",
+	C.AMDSMI_ACCELERATOR_JPEG:    "JPEG",
+}
+
+func (a *amdsmiBackend) Init() error {
+	ret := C.amdsmi_init(C.AMDSMI_INIT_AMD_GPUS)
+	return newStatusError("amdsmi_init", int(ret))
+}
+
+func (a *amdsmiBackend) Shutdown() error {
+	a.Lock()
+	a.sockets = nil
+	a.Unlock()
+	ret := C.amdsmi_shut_down()
+	return newStatusError("amdsmi_shut_down", int(ret))
+}
+
+func (a *amdsmiBackend) GetGPUCount() (int, error) {
+	var socketCount C.uint32_t
+	ret := C.amdsmi_get_socket_handles(&socketCount, nil)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		log_e.Errorf("Failed to get socket count")
+		return 0, newStatusError("amdsmi_get_socket_handles", int(ret))
+	}
+	if socketCount == 0 {
+		a.Lock()
+		a.sockets = nil
+		a.Unlock()
+		return 0, nil
+	}
+
+	// allocating the memory for the sockets
+	sockets := make([]C.amdsmi_socket_handle, socketCount)
+
+	// get the actual socket handles
+	ret = C.amdsmi_get_socket_handles(&socketCount, &sockets[0])
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		log_e.Errorf("Failed to get socket handles")
+		return 0, newStatusError("amdsmi_get_socket_handles", int(ret))
+	}
+
+	a.Lock()
+	a.sockets = sockets[:socketCount]
+	a.Unlock()
+	return int(socketCount), nil
+}
+
+func (a *amdsmiBackend) processorHandles(gpuID int) ([]C.amdsmi_processor_handle, error) {
+	a.Lock()
+	if gpuID < 0 || gpuID >= len(a.sockets) {
+		a.Unlock()
+		return nil, &StatusError{Op: fmt.Sprintf("gpu %d lookup", gpuID), Code: StatusNotFound}
+	}
+	socket := a.sockets[gpuID]
+	a.Unlock()
+
+	var deviceCount C.uint32_t
+	ret := C.amdsmi_get_processor_handles(socket, &deviceCount, nil)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		log_e.Errorf("Failed to get device count")
+		return nil, newStatusError("amdsmi_get_processor_handles", int(ret))
+	}
+	if deviceCount == 0 {
+		return nil, &StatusError{Op: "amdsmi_get_processor_handles", Code: StatusNotFound}
+	}
+
+	// allocating the memory for the processor
+	processors := make([]C.amdsmi_processor_handle, deviceCount)
+
+	ret = C.amdsmi_get_processor_handles(socket, &deviceCount, &processors[0])
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		log_e.Errorf("Failed to get processor handles")
+		return nil, newStatusError("amdsmi_get_processor_handles", int(ret))
+	}
+	return processors[:deviceCount], nil
+}
+
+// primaryHandle returns the first processor handle of a socket, partition
+// settings apply to the whole GPU and are read and written through it
+func (a *amdsmiBackend) primaryHandle(gpuID int) (C.amdsmi_processor_handle, error) {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return nil, err
+	}
+	return handles[0], nil
+}
+
+func (a *amdsmiBackend) GetProcessorCount(gpuID int) (int, error) {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return 0, err
+	}
+	return len(handles), nil
+}
+
+func (a *amdsmiBackend) GetProcessorType(gpuID int) (ProcessorType, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return ProcessorTypeUnknown, err
+	}
+	var processorType C.processor_type_t
+	ret := C.amdsmi_get_processor_type(handle, &processorType)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return ProcessorTypeUnknown, newStatusError("amdsmi_get_processor_type", int(ret))
+	}
+	return ProcessorType(processorType), nil
+}
+
+func (a *amdsmiBackend) GetComputePartition(gpuID int) (string, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return "", err
+	}
+	var len C.uint32_t = 4
+	computePartition := make([]C.char, len)
+	ret := C.amdsmi_get_gpu_compute_partition(handle, &computePartition[0], len)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return "", newStatusError("amdsmi_get_gpu_compute_partition", int(ret))
+	}
+	cStr := (*C.char)(unsafe.Pointer(&computePartition[0]))
+	return C.GoString(cStr), nil
+}
+
+func (a *amdsmiBackend) GetMemoryPartition(gpuID int) (string, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return "", err
+	}
+	var len C.uint32_t = 5
+	memoryPartition := make([]C.char, len)
+	ret := C.amdsmi_get_gpu_memory_partition(handle, &memoryPartition[0], len)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return "", newStatusError("amdsmi_get_gpu_memory_partition", int(ret))
+	}
+	cStr := (*C.char)(unsafe.Pointer(&memoryPartition[0]))
+	return C.GoString(cStr), nil
+}
+
+func (a *amdsmiBackend) SetComputePartition(gpuID int, partition string) error {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_compute_partition(handle, convertComputePartitonType(partition))
+	return newStatusError("amdsmi_set_gpu_compute_partition", int(ret))
+}
+
+func (a *amdsmiBackend) SetMemoryPartition(gpuID int, partition string) error {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_memory_partition(handle, convertMemoryPartitionType(partition))
+	return newStatusError("amdsmi_set_gpu_memory_partition", int(ret))
+}
+
+// GetPartitionCapabilities reads the memory partition caps and the compute
+// modes of the accelerator partition profiles of the GPU
+func (a *amdsmiBackend) GetPartitionCapabilities(gpuID int) (PartitionCapabilities, error) {
+	caps := PartitionCapabilities{}
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return caps, err
+	}
+	var memoryConfig C.amdsmi_memory_partition_config_t
+	ret := C.amdsmi_get_gpu_memory_partition_config(handle, &memoryConfig)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return caps, newStatusError("amdsmi_get_gpu_memory_partition_config", int(ret))
+	}
+	caps.MemoryPartitions = npsModes(uint32(*(*C.uint32_t)(unsafe.Pointer(&memoryConfig.partition_caps))))
+
+	var profileConfig C.amdsmi_accelerator_partition_profile_config_t
+	ret = C.amdsmi_get_gpu_accelerator_partition_profile_config(handle, &profileConfig)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return caps, newStatusError("amdsmi_get_gpu_accelerator_partition_profile_config", int(ret))
+	}
+	caps.ComputePartitions = []string{}
+	seen := make(map[string]bool)
+	for i := 0; i < int(profileConfig.num_profiles) && i < C.AMDSMI_MAX_ACCELERATOR_PROFILE; i++ {
+		name, ok := acceleratorPartitionNames[profileConfig.profiles[i].profile_type]
+		if ok && !seen[name] {
+			seen[name] = true
+			caps.ComputePartitions = append(caps.ComputePartitions, name)
+		}
+	}
+	return caps, nil
+}
+
+func (a *amdsmiBackend) GetASICInfo(gpuID int) (ASICInfo, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return ASICInfo{}, err
+	}
+	var info C.amdsmi_asic_info_t
+	ret := C.amdsmi_get_gpu_asic_info(handle, &info)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return ASICInfo{}, newStatusError("amdsmi_get_gpu_asic_info", int(ret))
+	}
+	return ASICInfo{
+		MarketName: C.GoString(&info.market_name[0]),
+		DeviceID:   uint64(info.device_id),
+	}, nil
+}
+
+func (a *amdsmiBackend) GetAcceleratorProfiles(gpuID int) ([]AcceleratorProfile, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return nil, err
+	}
+	var config C.amdsmi_accelerator_partition_profile_config_t
+	ret := C.amdsmi_get_gpu_accelerator_partition_profile_config(handle, &config)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return nil, newStatusError("amdsmi_get_gpu_accelerator_partition_profile_config", int(ret))
+	}
+	profiles := []AcceleratorProfile{}
+	for i := 0; i < int(config.num_profiles) && i < C.AMDSMI_MAX_ACCELERATOR_PROFILE; i++ {
+		p := config.profiles[i]
+		profile := AcceleratorProfile{
+			Index:            int(p.profile_index),
+			Type:             acceleratorPartitionNames[p.profile_type],
+			NumPartitions:    int(p.num_partitions),
+			MemoryPartitions: npsModes(uint32(*(*C.uint32_t)(unsafe.Pointer(&p.memory_caps)))),
+			Resources:        make(map[string]int),
+		}
+		// partitions of a profile get the same resources, read those of the first
+		for r := 0; r < int(p.num_resources) && r < C.AMDSMI_MAX_CP_PROFILE_RESOURCES; r++ {
+			idx := int(p.resources[0][r])
+			if idx >= int(config.num_resource_profiles) || idx >= C.AMDSMI_MAX_CP_PROFILE_RESOURCES {
+				continue
+			}
+			rp := config.resource_profiles[idx]
+			if name, ok := acceleratorResourceNames[rp.resource_type]; ok {
+				profile.Resources[name] = int(rp.partition_resource)
+			}
+		}
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (a *amdsmiBackend) GetAcceleratorProfileIndex(gpuID int) (int, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return 0, err
+	}
+	var profile C.amdsmi_accelerator_partition_profile_t
+	partitionIDs := make([]C.uint32_t, C.AMDSMI_MAX_ACCELERATOR_PARTITIONS)
+	ret := C.amdsmi_get_gpu_accelerator_partition_profile(handle, &profile, &partitionIDs[0])
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return 0, newStatusError("amdsmi_get_gpu_accelerator_partition_profile", int(ret))
+	}
+	return int(profile.profile_index), nil
+}
+
+func (a *amdsmiBackend) SetAcceleratorProfile(gpuID int, profileIndex int) error {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_accelerator_partition_profile(handle, C.uint32_t(profileIndex))
+	return newStatusError("amdsmi_set_gpu_accelerator_partition_profile", int(ret))
+}
+
+// GetPartitionMemory reads the VRAM total of every processor handle of the
+// socket, each handle is one partition
+func (a *amdsmiBackend) GetPartitionMemory(gpuID int) ([]uint64, error) {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return nil, err
+	}
+	memory := make([]uint64, len(handles))
+	for i, handle := range handles {
+		var total C.uint64_t
+		ret := C.amdsmi_get_gpu_memory_total(handle, C.AMDSMI_MEM_TYPE_VRAM, &total)
+		if ret != C.AMDSMI_STATUS_SUCCESS {
+			return nil, newStatusError("amdsmi_get_gpu_memory_total", int(ret))
+		}
+		memory[i] = uint64(total)
+	}
+	return memory, nil
+}
+
+func (a *amdsmiBackend) GetPowerCapInfo(gpuID int) (PowerCapInfo, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return PowerCapInfo{}, err
+	}
+	var info C.amdsmi_power_cap_info_t
+	ret := C.amdsmi_get_power_cap_info(handle, 0, &info)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return PowerCapInfo{}, newStatusError("amdsmi_get_power_cap_info", int(ret))
+	}
+	return PowerCapInfo{
+		PowerCap:        uint64(info.power_cap),
+		DefaultPowerCap: uint64(info.default_power_cap),
+		MinPowerCap:     uint64(info.min_power_cap),
+		MaxPowerCap:     uint64(info.max_power_cap),
+	}, nil
+}
+
+func (a *amdsmiBackend) SetPowerCap(gpuID int, powerCap uint64) error {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_power_cap(handle, 0, C.uint64_t(powerCap))
+	return newStatusError("amdsmi_set_power_cap", int(ret))
+}
+
+func convertClockType(clock string) (C.amdsmi_clk_type_t, error) {
+	switch clock {
+	case ClockGFX:
+		return C.AMDSMI_CLK_TYPE_GFX, nil
+	case ClockMEM:
+		return C.AMDSMI_CLK_TYPE_MEM, nil
+	default:
+		return 0, newStatusError(fmt.Sprintf("clock type %v", clock), StatusInval)
+	}
+}
+
+func (a *amdsmiBackend) GetClockInfo(gpuID int, clock string) (ClockInfo, error) {
+	if _, err := convertClockType(clock); err != nil {
+		return ClockInfo{}, err
+	}
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return ClockInfo{}, err
+	}
+	var data C.amdsmi_od_volt_freq_data_t
+	ret := C.amdsmi_get_gpu_od_volt_info(handle, &data)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return ClockInfo{}, newStatusError("amdsmi_get_gpu_od_volt_info", int(ret))
+	}
+	current, limits := data.curr_sclk_range, data.sclk_freq_limits
+	if clock == ClockMEM {
+		current, limits = data.curr_mclk_range, data.mclk_freq_limits
+	}
+	return ClockInfo{
+		Current: ClockRange{Min: uint64(current.lower_bound), Max: uint64(current.upper_bound)},
+		Limits:  ClockRange{Min: uint64(limits.lower_bound), Max: uint64(limits.upper_bound)},
+	}, nil
+}
+
+func (a *amdsmiBackend) SetClockRange(gpuID int, clock string, clockRange ClockRange) error {
+	clkType, err := convertClockType(clock)
+	if err != nil {
+		return err
+	}
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	if clockRange.Min != 0 && clockRange.Max != 0 {
+		ret := C.amdsmi_set_gpu_clk_range(handle, C.uint64_t(clockRange.Min), C.uint64_t(clockRange.Max), clkType)
+		return newStatusError("amdsmi_set_gpu_clk_range", int(ret))
+	}
+	if clockRange.Min != 0 {
+		ret := C.amdsmi_set_gpu_clk_limit(handle, clkType, C.CLK_LIMIT_MIN, C.uint64_t(clockRange.Min))
+		if ret != C.AMDSMI_STATUS_SUCCESS {
+			return newStatusError("amdsmi_set_gpu_clk_limit", int(ret))
+		}
+	}
+	if clockRange.Max != 0 {
+		ret := C.amdsmi_set_gpu_clk_limit(handle, clkType, C.CLK_LIMIT_MAX, C.uint64_t(clockRange.Max))
+		if ret != C.AMDSMI_STATUS_SUCCESS {
+			return newStatusError("amdsmi_set_gpu_clk_limit", int(ret))
+		}
+	}
+	return nil
+}
+
+func (a *amdsmiBackend) GetPerfLevel(gpuID int) (string, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return "", err
+	}
+	var level C.amdsmi_dev_perf_level_t
+	ret := C.amdsmi_get_gpu_perf_level(handle, &level)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return "", newStatusError("amdsmi_get_gpu_perf_level", int(ret))
+	}
+	if int(level) >= len(PerfLevels) {
+		return "UNKNOWN", nil
+	}
+	return PerfLevels[level], nil
+}
+
+func (a *amdsmiBackend) SetPerfLevel(gpuID int, level string) error {
+	index := perfLevelIndex(level)
+	if index < 0 || level == PerfLevelDeterminism {
+		return newStatusError(fmt.Sprintf("performance level %v", level), StatusInval)
+	}
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_perf_level(handle, C.amdsmi_dev_perf_level_t(index))
+	return newStatusError("amdsmi_set_gpu_perf_level", int(ret))
+}
+
+func (a *amdsmiBackend) SetPerfDeterminismMode(gpuID int, gfxClockMax uint64) error {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_perf_determinism_mode(handle, C.uint64_t(gfxClockMax))
+	return newStatusError("amdsmi_set_gpu_perf_determinism_mode", int(ret))
+}
+
+func (a *amdsmiBackend) GetPowerProfile(gpuID int) (PowerProfileInfo, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return PowerProfileInfo{}, err
+	}
+	var status C.amdsmi_power_profile_status_t
+	ret := C.amdsmi_get_gpu_power_profile_presets(handle, 0, &status)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return PowerProfileInfo{}, newStatusError("amdsmi_get_gpu_power_profile_presets", int(ret))
+	}
+	info := PowerProfileInfo{Available: powerProfileModes(uint64(status.available_profiles))}
+	if current := powerProfileModes(uint64(status.current)); len(current) == 1 {
+		info.Current = current[0]
+	}
+	return info, nil
+}
+
+func (a *amdsmiBackend) SetPowerProfile(gpuID int, profile string) error {
+	mask := powerProfileMask(profile)
+	if mask == 0 {
+		return newStatusError(fmt.Sprintf("power profile %v", profile), StatusInval)
+	}
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return err
+	}
+	ret := C.amdsmi_set_gpu_power_profile(handle, 0, C.amdsmi_power_profile_preset_masks_t(mask))
+	return newStatusError("amdsmi_set_gpu_power_profile", int(ret))
+}
+
+// GetProcessIsolation reads the isolation of every processor handle, each
+// partition is isolated on its own
+func (a *amdsmiBackend) GetProcessIsolation(gpuID int) (bool, error) {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return false, err
+	}
+	for _, handle := range handles {
+		var isolation C.uint32_t
+		ret := C.amdsmi_get_gpu_process_isolation(handle, &isolation)
+		if ret != C.AMDSMI_STATUS_SUCCESS {
+			return false, newStatusError("amdsmi_get_gpu_process_isolation", int(ret))
+		}
+		if isolation == 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (a *amdsmiBackend) SetProcessIsolation(gpuID int, enabled bool) error {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return err
+	}
+	isolation := C.uint32_t(0)
+	if enabled {
+		isolation = 1
+	}
+	for _, handle := range handles {
+		ret := C.amdsmi_set_gpu_process_isolation(handle, isolation)
+		if ret != C.AMDSMI_STATUS_SUCCESS {
+			return newStatusError("amdsmi_set_gpu_process_isolation", int(ret))
+		}
+	}
+	return nil
+}
+
+// GetProcesses merges the process lists of all processor handles, a process
+// using several partitions is listed once
+func (a *amdsmiBackend) GetProcesses(gpuID int) ([]GPUProcess, error) {
+	handles, err := a.processorHandles(gpuID)
+	if err != nil {
+		return nil, err
+	}
+	processes := []GPUProcess{}
+	index := map[int]int{}
+	for _, handle := range handles {
+		var count C.uint32_t
+		ret := C.amdsmi_get_gpu_process_list(handle, &count, nil)
+		if ret != C.AMDSMI_STATUS_SUCCESS && ret != C.AMDSMI_STATUS_OUT_OF_RESOURCES {
+			return nil, newStatusError("amdsmi_get_gpu_process_list", int(ret))
+		}
+		if count == 0 {
+			continue
+		}
+		list := make([]C.amdsmi_proc_info_t, count)
+		ret = C.amdsmi_get_gpu_process_list(handle, &count, &list[0])
+		// processes started in between are left out
+		if ret != C.AMDSMI_STATUS_SUCCESS && ret != C.AMDSMI_STATUS_OUT_OF_RESOURCES {
+			return nil, newStatusError("amdsmi_get_gpu_process_list", int(ret))
+		}
+		for _, info := range list[:min(int(count), len(list))] {
+			pid := int(info.pid)
+			if i, ok := index[pid]; ok {
+				processes[i].MemoryBytes += uint64(info.mem)
+				continue
+			}
+			index[pid] = len(processes)
+			processes = append(processes, GPUProcess{PID: pid, Name: C.GoString(&info.name[0]), MemoryBytes: uint64(info.mem)})
+		}
+	}
+	return processes, nil
+}
+
+func (a *amdsmiBackend) GetPCIAddress(gpuID int) (string, error) {
+	handle, err := a.primaryHandle(gpuID)
+	if err != nil {
+		return "", err
+	}
+	var bdf C.amdsmi_bdf_t
+	ret := C.amdsmi_get_gpu_device_bdf(handle, &bdf)
+	if ret != C.AMDSMI_STATUS_SUCCESS {
+		return "", newStatusError("amdsmi_get_gpu_device_bdf", int(ret))
+	}
+	// amdsmi_bdf_t is a union, cgo exposes it as raw bytes
+	return formatPCIAddress(*(*uint64)(unsafe.Pointer(&bdf))), nil
+}
@@ -0,0 +1,26 @@
+//go:build !amdsmi
+
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import "errors"
+
+// binaries built without the amdsmi tag carry no libamd_smi dependency
+func newAMDSMIBackend() (GPUBackend, error) {
+	return nil, errors.New("amdsmi backend not available, binary was built without the amdsmi build tag")
+}
@@ -0,0 +1,352 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// backend names accepted by New
+const (
+	BackendAMDSMI = "amdsmi"
+	BackendSysfs  = "sysfs"
+	BackendSim    = "sim"
+
+	// environment variable used to select the backend
+	BackendEnv = "DCM_GPU_BACKEND"
+)
+
+// AMD SMI status codes returned by the backends, see amdsmi_status_t in amdsmi.h
+const (
+	StatusSuccess        = 0
+	StatusInval          = 1
+	StatusNotSupported   = 2
+	StatusNoPerm         = 10
+	StatusFileError      = 14
+	StatusBusy           = 30
+	StatusNotFound       = 31
+	StatusNotInit        = 32
+	StatusUnexpectedData = 43
+	StatusUnknownError   = 0xFFFFFFFF
+)
+
+// ComputePartitionCounts is the number of partitions each compute partition
+// mode splits a GPU into, per GPU model
+var ComputePartitionCounts = map[string]map[string]int{
+	"MI300X": {"SPX": 1, "DPX": 2, "QPX": 4, "CPX": 8},
+	"MI325X": {"SPX": 1, "DPX": 2, "QPX": 4, "CPX": 8},
+	"MI300A": {"SPX": 1, "TPX": 3, "CPX": 6},
+	"MI308X": {"SPX": 1, "DPX": 2, "CPX": 4},
+}
+
+// GPU models of the PCI device ids, used when the market name is not known
+var ASICDeviceModels = map[uint64]string{
+	0x74a0: "MI300A",
+	0x74a1: "MI300X",
+	0x74a5: "MI325X",
+	0x74b5: "MI300X",
+}
+
+// ASICModel resolves the GPU model from the market name, e.g. "AMD Instinct
+// MI300X", falling back to the PCI device id, empty when it is not known
+func ASICModel(info ASICInfo) string {
+	name := strings.ToUpper(info.MarketName)
+	models := make([]string, 0, len(ComputePartitionCounts))
+	for model := range ComputePartitionCounts {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		if strings.Contains(name, model) {
+			return model
+		}
+	}
+	return ASICDeviceModels[info.DeviceID]
+}
+
+// PartitionCount returns the number of partitions the compute partition mode
+// splits a GPU of the model into, false when it is not known
+func PartitionCount(model, compute string) (int, bool) {
+	count, ok := ComputePartitionCounts[model][compute]
+	return count, ok
+}
+
+type ProcessorType int
+
+// processor types, see processor_type_t in amdsmi.h
+const (
+	ProcessorTypeUnknown ProcessorType = iota
+	ProcessorTypeAMDGPU
+	ProcessorTypeAMDCPU
+	ProcessorTypeNonAMDGPU
+	ProcessorTypeNonAMDCPU
+	ProcessorTypeAMDCPUCore
+	ProcessorTypeAMDAPU
+)
+
+// GPUBackend is the device access layer used for GPU partitioning.
+// GPUs are addressed by their index in the socket enumeration order.
+type GPUBackend interface {
+	// Init prepares the backend for use, must be called before any other method
+	Init() error
+	// Shutdown releases the resources acquired in Init
+	Shutdown() error
+	// GetGPUCount enumerates the GPUs on the node and returns their count
+	GetGPUCount() (int, error)
+	// GetProcessorCount returns the number of processor handles (partitions) of a GPU
+	GetProcessorCount(gpuID int) (int, error)
+	GetProcessorType(gpuID int) (ProcessorType, error)
+	GetComputePartition(gpuID int) (string, error)
+	GetMemoryPartition(gpuID int) (string, error)
+	SetComputePartition(gpuID int, partition string) error
+	SetMemoryPartition(gpuID int, partition string) error
+	// GetPartitionCapabilities returns the partition modes the GPU supports
+	GetPartitionCapabilities(gpuID int) (PartitionCapabilities, error)
+	// GetASICInfo identifies the GPU model
+	GetASICInfo(gpuID int) (ASICInfo, error)
+	// GetAcceleratorProfiles lists the accelerator partition profiles of the GPU
+	GetAcceleratorProfiles(gpuID int) ([]AcceleratorProfile, error)
+	// GetAcceleratorProfileIndex returns the index of the current profile
+	GetAcceleratorProfileIndex(gpuID int) (int, error)
+	SetAcceleratorProfile(gpuID int, profileIndex int) error
+	// GetPartitionMemory returns the VRAM size in bytes of each partition
+	GetPartitionMemory(gpuID int) ([]uint64, error)
+	GetPowerCapInfo(gpuID int) (PowerCapInfo, error)
+	// SetPowerCap sets the power cap of the GPU in microwatts
+	SetPowerCap(gpuID int, powerCap uint64) error
+	// GetClockInfo returns the soft limits of a clock domain, GFX or MEM
+	GetClockInfo(gpuID int, clock string) (ClockInfo, error)
+	// SetClockRange sets the soft limits of a clock domain, a 0 bound is left
+	// unchanged
+	SetClockRange(gpuID int, clock string, clockRange ClockRange) error
+	GetPerfLevel(gpuID int) (string, error)
+	SetPerfLevel(gpuID int, level string) error
+	// SetPerfDeterminismMode switches to the DETERMINISM performance level
+	// with the given GFX clock soft maximum in MHz
+	SetPerfDeterminismMode(gpuID int, gfxClockMax uint64) error
+	GetPowerProfile(gpuID int) (PowerProfileInfo, error)
+	SetPowerProfile(gpuID int, profile string) error
+	// GetProcessIsolation reports whether process isolation is enabled on
+	// all partitions of the GPU
+	GetProcessIsolation(gpuID int) (bool, error)
+	SetProcessIsolation(gpuID int, enabled bool) error
+	// GetProcesses lists the processes using any partition of the GPU
+	GetProcesses(gpuID int) ([]GPUProcess, error)
+	// GetPCIAddress returns the PCI address of the GPU, e.g. 0000:0c:00.0
+	GetPCIAddress(gpuID int) (string, error)
+}
+
+// GPUProcess is a process using a GPU, see amdsmi_proc_info_t in amdsmi.h
+type GPUProcess struct {
+	PID  int
+	Name string
+	// memory of the GPU used by the process in bytes
+	MemoryBytes uint64
+}
+
+// PowerProfiles are the power profile presets in
+// amdsmi_power_profile_preset_masks_t bit order
+var PowerProfiles = []string{"CUSTOM", "VIDEO", "POWER_SAVING", "COMPUTE", "VR", "3D_FULL_SCREEN", "BOOTUP_DEFAULT"}
+
+// PowerProfileInfo is the active power profile preset of a GPU and the
+// presets it supports, see amdsmi_power_profile_status_t in amdsmi.h
+type PowerProfileInfo struct {
+	Current   string
+	Available []string
+}
+
+// clock domains accepted by GetClockInfo and SetClockRange
+const (
+	ClockGFX = "GFX"
+	ClockMEM = "MEM"
+)
+
+// PerfLevels are the performance levels of a GPU in amdsmi_dev_perf_level_t
+// order
+var PerfLevels = []string{"AUTO", "LOW", "HIGH", "MANUAL", "STABLE_STD", "STABLE_PEAK",
+	"STABLE_MIN_MCLK", "STABLE_MIN_SCLK", "DETERMINISM"}
+
+// PerfLevelDeterminism is set through SetPerfDeterminismMode only
+const PerfLevelDeterminism = "DETERMINISM"
+
+// ClockRange is a clock frequency range in MHz
+type ClockRange struct {
+	Min uint64
+	Max uint64
+}
+
+// ClockInfo is the current soft range of a clock domain and the range the
+// GPU accepts, see amdsmi_od_volt_freq_data_t in amdsmi.h
+type ClockInfo struct {
+	Current ClockRange
+	Limits  ClockRange
+}
+
+// PowerCapInfo is the power cap of a GPU and its valid range in microwatts,
+// see amdsmi_power_cap_info_t in amdsmi.h
+type PowerCapInfo struct {
+	PowerCap        uint64
+	DefaultPowerCap uint64
+	MinPowerCap     uint64
+	MaxPowerCap     uint64
+}
+
+// AcceleratorProfile is an accelerator partition profile of a GPU, see
+// amdsmi_accelerator_partition_profile_t in amdsmi.h
+type AcceleratorProfile struct {
+	Index int
+	// compute partition mode of the profile, e.g. CPX
+	Type          string
+	NumPartitions int
+	// memory partition modes the profile can be used with
+	MemoryPartitions []string
+	// resources each partition gets by resource type, e.g. XCC
+	Resources map[string]int
+}
+
+// ASICInfo identifies a GPU model, see amdsmi_asic_info_t in amdsmi.h
+type ASICInfo struct {
+	MarketName string
+	// PCI device id
+	DeviceID uint64
+}
+
+// PartitionCapabilities lists the compute and memory partition modes a GPU
+// model accepts, an empty list means the backend could not tell
+type PartitionCapabilities struct {
+	ComputePartitions []string
+	MemoryPartitions  []string
+}
+
+// npsModes converts an amdsmi_nps_caps_t bitmask into memory partition modes
+func npsModes(mask uint32) []string {
+	modes := []string{}
+	for bit, mode := range []string{"NPS1", "NPS2", "NPS4", "NPS8"} {
+		if mask&(1<<bit) != 0 {
+			modes = append(modes, mode)
+		}
+	}
+	return modes
+}
+
+// powerProfileModes converts a power profile preset bitmask into preset names
+func powerProfileModes(mask uint64) []string {
+	profiles := []string{}
+	for bit, profile := range PowerProfiles {
+		if mask&(1<<bit) != 0 {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
+
+// powerProfileMask returns the preset bit of a power profile, 0 when unknown
+func powerProfileMask(profile string) uint64 {
+	for bit, p := range PowerProfiles {
+		if p == profile {
+			return 1 << bit
+		}
+	}
+	return 0
+}
+
+// perfLevelIndex returns the position of a performance level in PerfLevels
+func perfLevelIndex(level string) int {
+	for i, l := range PerfLevels {
+		if l == level {
+			return i
+		}
+	}
+	return -1
+}
+
+// splitModes parses a comma separated list of partition modes
+func splitModes(list string) []string {
+	modes := []string{}
+	for _, m := range strings.Split(list, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			modes = append(modes, m)
+		}
+	}
+	return modes
+}
+
+// formatPCIAddress formats a PCI address in amdsmi_bdf_t layout, the domain
+// above bit 16, then the bus, device and function bits
+func formatPCIAddress(bdf uint64) string {
+	return fmt.Sprintf("%04x:%02x:%02x.%x", bdf>>16, (bdf>>8)&0xff, (bdf>>3)&0x1f, bdf&0x7)
+}
+
+// StatusError reports a failed backend call with its amdsmi status code
+type StatusError struct {
+	Op   string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed with status %d", e.Op, e.Code)
+}
+
+func newStatusError(op string, code int) error {
+	if code == StatusSuccess {
+		return nil
+	}
+	return &StatusError{Op: op, Code: code}
+}
+
+// StatusCode returns the amdsmi status code carried by err
+func StatusCode(err error) int {
+	if err == nil {
+		return StatusSuccess
+	}
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code
+	}
+	return StatusUnknownError
+}
+
+// IsBusy reports whether err was caused by the GPU being in use
+func IsBusy(err error) bool {
+	return StatusCode(err) == StatusBusy
+}
+
+// NameFromEnv returns the backend selected through the environment, amdsmi by default
+func NameFromEnv() string {
+	if name := strings.ToLower(os.Getenv(BackendEnv)); name != "" {
+		return name
+	}
+	return BackendAMDSMI
+}
+
+// New creates the backend with the given name
+func New(name string) (GPUBackend, error) {
+	switch name {
+	case BackendAMDSMI:
+		return newAMDSMIBackend()
+	case BackendSysfs:
+		return NewSysfsBackend(SysfsRootFromEnv()), nil
+	case BackendSim:
+		return NewSimBackend(SimConfigFromEnv()), nil
+	default:
+		return nil, fmt.Errorf("unknown gpu backend %q", name)
+	}
+}
@@ -0,0 +1,625 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// environment variables read by SimConfigFromEnv
+const (
+	SimGPUCountEnv        = "DCM_SIM_GPU_COUNT"
+	SimBusyGPUsEnv        = "DCM_SIM_BUSY_GPUS"
+	SimMemoryPartDelayEnv = "DCM_SIM_MEMORY_PARTITION_DELAY"
+	SimComputeModesEnv    = "DCM_SIM_COMPUTE_PARTITIONS"
+	SimMemoryModesEnv     = "DCM_SIM_MEMORY_PARTITIONS"
+	SimMarketNameEnv      = "DCM_SIM_MARKET_NAME"
+	simDefaultMarketName  = "AMD Instinct MI300X"
+	simDefaultDeviceID    = 0x74a1
+	simDefaultGPUCount    = 8
+	simDefaultCompute     = "SPX"
+	simDefaultMemory      = "NPS1"
+)
+
+// modes supported by a simulated MI300X unless configured otherwise
+var (
+	simComputePartitions = []string{"SPX", "DPX", "QPX", "CPX"}
+	simMemoryPartitions  = []string{"NPS1", "NPS2", "NPS4"}
+	// memory partitions each accelerator profile of a MI300X can be used with
+	simProfileMemory = map[string][]string{"SPX": {"NPS1"}, "DPX": {"NPS1", "NPS2"}, "TPX": {"NPS1"}, "QPX": {"NPS1"}, "CPX": {"NPS1", "NPS4"}}
+)
+
+const (
+	simXCCCount  = 8
+	simVRAMTotal = 192 << 30
+	// power cap range in microwatts
+	simPowerCap    = 750000000
+	simMinPowerCap = 200000000
+)
+
+// power profile presets the simulated GPUs support
+var simPowerProfiles = []string{"CUSTOM", "POWER_SAVING", "COMPUTE", "BOOTUP_DEFAULT"}
+
+// clock ranges in MHz the simulated GPUs accept, also their initial limits
+var simClockLimits = map[string]ClockRange{
+	ClockGFX: {Min: 500, Max: 2100},
+	ClockMEM: {Min: 900, Max: 1300},
+}
+
+// SimConfig describes the node modelled by the simulated backend
+type SimConfig struct {
+	// number of GPUs on the node
+	NumGPUs int
+	// partition modes all GPUs start in
+	ComputePartition string
+	MemoryPartition  string
+	// number of set calls on a GPU that fail with AMDSMI_STATUS_BUSY,
+	// a negative value keeps the GPU busy forever
+	BusyGPUs map[int]int
+	// time a memory partition change takes before it is reported back
+	MemoryPartitionDelay time.Duration
+	// status returned by Init, used to model library load failures
+	InitStatus int
+	// partition modes the GPU model supports, set calls with other modes
+	// fail with AMDSMI_STATUS_INVAL
+	SupportedComputePartitions []string
+	SupportedMemoryPartitions  []string
+	// GPU model reported by GetASICInfo, MI300X by default
+	MarketName string
+	DeviceID   uint64
+	// processes reported on each GPU
+	Processes map[int][]GPUProcess
+}
+
+type simGPU struct {
+	compute       string
+	memory        string
+	pendingMemory string
+	memoryReadyAt time.Time
+	busy          int
+	powerCap      uint64
+	clocks        map[string]ClockRange
+	perfLevel     string
+	powerProfile  string
+	isolation     bool
+}
+
+// SimBackend is an in-memory GPUBackend modelling a node of MI300 class GPUs
+type SimBackend struct {
+	sync.Mutex
+	cfg         SimConfig
+	gpus        []*simGPU
+	initialized bool
+}
+
+// SimConfigFromEnv builds the simulator config from DCM_SIM_* variables
+func SimConfigFromEnv() SimConfig {
+	cfg := SimConfig{NumGPUs: simDefaultGPUCount}
+	if v := os.Getenv(SimGPUCountEnv); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			cfg.NumGPUs = n
+		} else {
+			log.Printf("Ignoring invalid %s value %q", SimGPUCountEnv, v)
+		}
+	}
+	if v := os.Getenv(SimBusyGPUsEnv); v != "" {
+		cfg.BusyGPUs = make(map[int]int)
+		for _, id := range strings.Split(v, ",") {
+			if n, err := strconv.Atoi(strings.TrimSpace(id)); err == nil {
+				cfg.BusyGPUs[n] = -1
+			}
+		}
+	}
+	if v := os.Getenv(SimMemoryPartDelayEnv); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MemoryPartitionDelay = d
+		} else {
+			log.Printf("Ignoring invalid %s value %q", SimMemoryPartDelayEnv, v)
+		}
+	}
+	if v := os.Getenv(SimComputeModesEnv); v != "" {
+		cfg.SupportedComputePartitions = splitModes(v)
+	}
+	if v := os.Getenv(SimMemoryModesEnv); v != "" {
+		cfg.SupportedMemoryPartitions = splitModes(v)
+	}
+	cfg.MarketName = os.Getenv(SimMarketNameEnv)
+	return cfg
+}
+
+func NewSimBackend(cfg SimConfig) *SimBackend {
+	if cfg.ComputePartition == "" {
+		cfg.ComputePartition = simDefaultCompute
+	}
+	if cfg.MemoryPartition == "" {
+		cfg.MemoryPartition = simDefaultMemory
+	}
+	if cfg.MarketName == "" {
+		cfg.MarketName = simDefaultMarketName
+		cfg.DeviceID = simDefaultDeviceID
+	}
+	if len(cfg.SupportedComputePartitions) == 0 {
+		cfg.SupportedComputePartitions = simComputePartitions
+	}
+	if len(cfg.SupportedMemoryPartitions) == 0 {
+		cfg.SupportedMemoryPartitions = simMemoryPartitions
+	}
+	s := &SimBackend{cfg: cfg}
+	for i := 0; i < cfg.NumGPUs; i++ {
+		s.gpus = append(s.gpus, &simGPU{
+			compute:  cfg.ComputePartition,
+			memory:   cfg.MemoryPartition,
+			busy:     cfg.BusyGPUs[i],
+			powerCap: simPowerCap,
+			clocks: map[string]ClockRange{
+				ClockGFX: simClockLimits[ClockGFX],
+				ClockMEM: simClockLimits[ClockMEM],
+			},
+			perfLevel:    "AUTO",
+			powerProfile: "BOOTUP_DEFAULT",
+		})
+	}
+	return s
+}
+
+func (s *SimBackend) Init() error {
+	s.Lock()
+	defer s.Unlock()
+	if err := newStatusError("amdsmi_init", s.cfg.InitStatus); err != nil {
+		return err
+	}
+	s.initialized = true
+	return nil
+}
+
+func (s *SimBackend) Shutdown() error {
+	s.Lock()
+	defer s.Unlock()
+	s.initialized = false
+	return nil
+}
+
+func (s *SimBackend) GetGPUCount() (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	if !s.initialized {
+		return 0, &StatusError{Op: "get gpu count", Code: StatusNotInit}
+	}
+	return len(s.gpus), nil
+}
+
+// partitionCount returns the partitions of a compute partition mode on the
+// simulated GPU model, models without a known count are modelled as MI300X
+func (s *SimBackend) partitionCount(compute string) int {
+	model := ASICModel(ASICInfo{MarketName: s.cfg.MarketName, DeviceID: s.cfg.DeviceID})
+	if _, ok := ComputePartitionCounts[model]; !ok {
+		model = "MI300X"
+	}
+	return ComputePartitionCounts[model][compute]
+}
+
+// gpu must be called with the lock held
+func (s *SimBackend) gpu(op string, gpuID int) (*simGPU, error) {
+	if !s.initialized {
+		return nil, &StatusError{Op: op, Code: StatusNotInit}
+	}
+	if gpuID < 0 || gpuID >= len(s.gpus) {
+		return nil, &StatusError{Op: fmt.Sprintf("%s gpu %d", op, gpuID), Code: StatusNotFound}
+	}
+	g := s.gpus[gpuID]
+	if g.pendingMemory != "" && !time.Now().Before(g.memoryReadyAt) {
+		g.memory = g.pendingMemory
+		g.pendingMemory = ""
+	}
+	return g, nil
+}
+
+func simSupports(modes []string, mode string) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// consumeBusy must be called with the lock held
+func (g *simGPU) consumeBusy(op string) error {
+	if g.busy == 0 {
+		return nil
+	}
+	if g.busy > 0 {
+		g.busy--
+	}
+	return &StatusError{Op: op, Code: StatusBusy}
+}
+
+func (s *SimBackend) GetProcessorCount(gpuID int) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get processor count", gpuID)
+	if err != nil {
+		return 0, err
+	}
+	return s.partitionCount(g.compute), nil
+}
+
+func (s *SimBackend) GetProcessorType(gpuID int) (ProcessorType, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get processor type", gpuID); err != nil {
+		return ProcessorTypeUnknown, err
+	}
+	return ProcessorTypeAMDGPU, nil
+}
+
+func (s *SimBackend) GetComputePartition(gpuID int) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get compute partition", gpuID)
+	if err != nil {
+		return "", err
+	}
+	return g.compute, nil
+}
+
+func (s *SimBackend) GetMemoryPartition(gpuID int) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get memory partition", gpuID)
+	if err != nil {
+		return "", err
+	}
+	return g.memory, nil
+}
+
+func (s *SimBackend) SetComputePartition(gpuID int, partition string) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set compute partition"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if !simSupports(s.cfg.SupportedComputePartitions, partition) {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	if err := g.consumeBusy(op); err != nil {
+		return err
+	}
+	g.setCompute(partition)
+	return nil
+}
+
+func (s *SimBackend) SetMemoryPartition(gpuID int, partition string) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set memory partition"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if !simSupports(s.cfg.SupportedMemoryPartitions, partition) {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	if err := g.consumeBusy(op); err != nil {
+		return err
+	}
+	if s.cfg.MemoryPartitionDelay <= 0 {
+		g.memory = partition
+		return nil
+	}
+	g.pendingMemory = partition
+	g.memoryReadyAt = time.Now().Add(s.cfg.MemoryPartitionDelay)
+	return nil
+}
+
+func (s *SimBackend) GetPartitionCapabilities(gpuID int) (PartitionCapabilities, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get partition capabilities", gpuID); err != nil {
+		return PartitionCapabilities{}, err
+	}
+	return PartitionCapabilities{
+		ComputePartitions: append([]string{}, s.cfg.SupportedComputePartitions...),
+		MemoryPartitions:  append([]string{}, s.cfg.SupportedMemoryPartitions...),
+	}, nil
+}
+
+func (s *SimBackend) GetASICInfo(gpuID int) (ASICInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get asic info", gpuID); err != nil {
+		return ASICInfo{}, err
+	}
+	return ASICInfo{MarketName: s.cfg.MarketName, DeviceID: s.cfg.DeviceID}, nil
+}
+
+// GetAcceleratorProfiles returns one profile per supported compute partition
+// in the configured order
+func (s *SimBackend) GetAcceleratorProfiles(gpuID int) ([]AcceleratorProfile, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get accelerator profiles", gpuID); err != nil {
+		return nil, err
+	}
+	profiles := []AcceleratorProfile{}
+	for i, compute := range s.cfg.SupportedComputePartitions {
+		count := s.partitionCount(compute)
+		if count == 0 {
+			continue
+		}
+		memory := []string{}
+		for _, m := range simProfileMemory[compute] {
+			if simSupports(s.cfg.SupportedMemoryPartitions, m) {
+				memory = append(memory, m)
+			}
+		}
+		profiles = append(profiles, AcceleratorProfile{
+			Index:            i,
+			Type:             compute,
+			NumPartitions:    count,
+			MemoryPartitions: memory,
+			Resources:        map[string]int{"XCC": simXCCCount / count},
+		})
+	}
+	return profiles, nil
+}
+
+func (s *SimBackend) GetAcceleratorProfileIndex(gpuID int) (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	op := "get accelerator profile"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return 0, err
+	}
+	for i, compute := range s.cfg.SupportedComputePartitions {
+		if compute == g.compute {
+			return i, nil
+		}
+	}
+	return 0, &StatusError{Op: op, Code: StatusNotFound}
+}
+
+func (s *SimBackend) SetAcceleratorProfile(gpuID int, profileIndex int) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set accelerator profile"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if profileIndex < 0 || profileIndex >= len(s.cfg.SupportedComputePartitions) {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	if err := g.consumeBusy(op); err != nil {
+		return err
+	}
+	g.setCompute(s.cfg.SupportedComputePartitions[profileIndex])
+	return nil
+}
+
+// GetPartitionMemory splits the VRAM evenly between the partitions
+func (s *SimBackend) GetPartitionMemory(gpuID int) ([]uint64, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get partition memory", gpuID)
+	if err != nil {
+		return nil, err
+	}
+	count := s.partitionCount(g.compute)
+	memory := make([]uint64, count)
+	for i := range memory {
+		memory[i] = simVRAMTotal / uint64(count)
+	}
+	return memory, nil
+}
+
+func (s *SimBackend) GetPowerCapInfo(gpuID int) (PowerCapInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get power cap", gpuID)
+	if err != nil {
+		return PowerCapInfo{}, err
+	}
+	return PowerCapInfo{
+		PowerCap:        g.powerCap,
+		DefaultPowerCap: simPowerCap,
+		MinPowerCap:     simMinPowerCap,
+		MaxPowerCap:     simPowerCap,
+	}, nil
+}
+
+func (s *SimBackend) SetPowerCap(gpuID int, powerCap uint64) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set power cap"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if powerCap < simMinPowerCap || powerCap > simPowerCap {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	g.powerCap = powerCap
+	return nil
+}
+
+func (s *SimBackend) GetClockInfo(gpuID int, clock string) (ClockInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	op := "get clock info"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return ClockInfo{}, err
+	}
+	limits, ok := simClockLimits[clock]
+	if !ok {
+		return ClockInfo{}, &StatusError{Op: fmt.Sprintf("%s %s", op, clock), Code: StatusInval}
+	}
+	return ClockInfo{Current: g.clocks[clock], Limits: limits}, nil
+}
+
+func (s *SimBackend) SetClockRange(gpuID int, clock string, clockRange ClockRange) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set clock range"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	limits, ok := simClockLimits[clock]
+	if !ok {
+		return &StatusError{Op: fmt.Sprintf("%s %s", op, clock), Code: StatusInval}
+	}
+	current := g.clocks[clock]
+	if clockRange.Min != 0 {
+		current.Min = clockRange.Min
+	}
+	if clockRange.Max != 0 {
+		current.Max = clockRange.Max
+	}
+	if current.Min < limits.Min || current.Max > limits.Max || current.Min > current.Max {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	g.clocks[clock] = current
+	return nil
+}
+
+func (s *SimBackend) GetPerfLevel(gpuID int) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get performance level", gpuID)
+	if err != nil {
+		return "", err
+	}
+	return g.perfLevel, nil
+}
+
+func (s *SimBackend) SetPerfLevel(gpuID int, level string) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set performance level"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if perfLevelIndex(level) < 0 || level == PerfLevelDeterminism {
+		return &StatusError{Op: fmt.Sprintf("%s %s", op, level), Code: StatusInval}
+	}
+	g.perfLevel = level
+	return nil
+}
+
+func (s *SimBackend) SetPerfDeterminismMode(gpuID int, gfxClockMax uint64) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set performance determinism mode"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	gfx := g.clocks[ClockGFX]
+	if gfxClockMax < gfx.Min || gfxClockMax > simClockLimits[ClockGFX].Max {
+		return &StatusError{Op: op, Code: StatusInval}
+	}
+	gfx.Max = gfxClockMax
+	g.clocks[ClockGFX] = gfx
+	g.perfLevel = PerfLevelDeterminism
+	return nil
+}
+
+func (s *SimBackend) GetPowerProfile(gpuID int) (PowerProfileInfo, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get power profile", gpuID)
+	if err != nil {
+		return PowerProfileInfo{}, err
+	}
+	return PowerProfileInfo{Current: g.powerProfile, Available: simPowerProfiles}, nil
+}
+
+func (s *SimBackend) SetPowerProfile(gpuID int, profile string) error {
+	s.Lock()
+	defer s.Unlock()
+	op := "set power profile"
+	g, err := s.gpu(op, gpuID)
+	if err != nil {
+		return err
+	}
+	if !simSupports(simPowerProfiles, profile) {
+		return &StatusError{Op: fmt.Sprintf("%s %s", op, profile), Code: StatusInval}
+	}
+	g.powerProfile = profile
+	return nil
+}
+
+// setCompute switches the compute partition, isolation is set per partition
+// and the new partitions come up without it
+func (g *simGPU) setCompute(partition string) {
+	if g.compute != partition {
+		g.isolation = false
+	}
+	g.compute = partition
+}
+
+func (s *SimBackend) GetProcessIsolation(gpuID int) (bool, error) {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("get process isolation", gpuID)
+	if err != nil {
+		return false, err
+	}
+	return g.isolation, nil
+}
+
+func (s *SimBackend) SetProcessIsolation(gpuID int, enabled bool) error {
+	s.Lock()
+	defer s.Unlock()
+	g, err := s.gpu("set process isolation", gpuID)
+	if err != nil {
+		return err
+	}
+	g.isolation = enabled
+	return nil
+}
+
+func (s *SimBackend) GetProcesses(gpuID int) ([]GPUProcess, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get processes", gpuID); err != nil {
+		return nil, err
+	}
+	return append([]GPUProcess{}, s.cfg.Processes[gpuID]...), nil
+}
+
+// GetPCIAddress places the simulated GPUs on consecutive buses starting at 1
+func (s *SimBackend) GetPCIAddress(gpuID int) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if _, err := s.gpu("get pci address", gpuID); err != nil {
+		return "", err
+	}
+	return formatPCIAddress(uint64(gpuID+1) << 8), nil
+}
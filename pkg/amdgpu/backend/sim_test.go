@@ -0,0 +1,67 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSimBackendPartitioning(t *testing.T) {
+	sim := NewSimBackend(SimConfig{NumGPUs: 2})
+	_, err := sim.GetGPUCount()
+	assert.Equal(t, StatusNotInit, StatusCode(err))
+
+	assert.NoError(t, sim.Init())
+	count, err := sim.GetGPUCount()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.NoError(t, sim.SetComputePartition(1, "CPX"))
+	compute, _ := sim.GetComputePartition(1)
+	assert.Equal(t, "CPX", compute)
+	partitions, _ := sim.GetProcessorCount(1)
+	assert.Equal(t, 8, partitions)
+
+	assert.Equal(t, StatusInval, StatusCode(sim.SetComputePartition(0, "TPX")))
+	assert.Equal(t, StatusNotFound, StatusCode(sim.SetMemoryPartition(2, "NPS4")))
+}
+
+func TestSimBackendBusy(t *testing.T) {
+	sim := NewSimBackend(SimConfig{NumGPUs: 1, BusyGPUs: map[int]int{0: 1}})
+	assert.NoError(t, sim.Init())
+
+	err := sim.SetMemoryPartition(0, "NPS4")
+	assert.True(t, IsBusy(err))
+	assert.NoError(t, sim.SetMemoryPartition(0, "NPS4"))
+}
+
+func TestSimBackendSlowMemoryPartition(t *testing.T) {
+	sim := NewSimBackend(SimConfig{NumGPUs: 1, MemoryPartitionDelay: 50 * time.Millisecond})
+	assert.NoError(t, sim.Init())
+
+	assert.NoError(t, sim.SetMemoryPartition(0, "NPS4"))
+	memory, _ := sim.GetMemoryPartition(0)
+	assert.Equal(t, "NPS1", memory)
+
+	assert.Eventually(t, func() bool {
+		memory, _ := sim.GetMemoryPartition(0)
+		return memory == "NPS4"
+	}, time.Second, 10*time.Millisecond)
+}
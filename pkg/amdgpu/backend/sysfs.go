@@ -0,0 +1,481 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	// environment variable overriding the sysfs mount point, mainly for tests
+	SysfsRootEnv = "DCM_SYSFS_ROOT"
+	// environment variable disabling the sysfs fallback when amdsmi_init fails
+	SysfsFallbackEnv = "DCM_SYSFS_FALLBACK"
+	DefaultSysfsRoot = "/sys"
+
+	currentComputePartitionFile   = "current_compute_partition"
+	availableComputePartitionFile = "available_compute_partition"
+	currentMemoryPartitionFile    = "current_memory_partition"
+	availableMemoryPartitionFile  = "available_memory_partition"
+	deviceIDFile                  = "device"
+	productNameFile               = "product_name"
+	powerCapFile                  = "power1_cap"
+	powerCapDefaultFile           = "power1_cap_default"
+	powerCapMinFile               = "power1_cap_min"
+	powerCapMaxFile               = "power1_cap_max"
+	perfLevelFile                 = "power_dpm_force_performance_level"
+	enforceIsolationFile          = "enforce_isolation"
+)
+
+// SysfsBackend reads and sets partitions through the amdgpu driver sysfs
+// attributes under <root>/class/drm/card*/device
+type SysfsBackend struct {
+	sync.Mutex
+	root string
+	// device directories of the partitionable GPUs ordered by card index
+	devices []string
+}
+
+// SysfsRootFromEnv returns the sysfs mount point to use
+func SysfsRootFromEnv() string {
+	if root := os.Getenv(SysfsRootEnv); root != "" {
+		return root
+	}
+	return DefaultSysfsRoot
+}
+
+// SysfsFallbackEnabled reports whether the sysfs backend may be used when
+// amdsmi cannot be initialized, enabled unless DCM_SYSFS_FALLBACK=false
+func SysfsFallbackEnabled() bool {
+	return strings.ToLower(os.Getenv(SysfsFallbackEnv)) != "false"
+}
+
+func NewSysfsBackend(root string) *SysfsBackend {
+	return &SysfsBackend{root: root}
+}
+
+func cardIndex(name string) (int, bool) {
+	if !strings.HasPrefix(name, "card") {
+		return 0, false
+	}
+	idx, err := strconv.Atoi(strings.TrimPrefix(name, "card"))
+	return idx, err == nil
+}
+
+// sysfsStatus maps a sysfs access error to the matching amdsmi status code
+func sysfsStatus(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	code := StatusFileError
+	switch {
+	case errors.Is(err, syscall.EBUSY):
+		code = StatusBusy
+	case errors.Is(err, syscall.EINVAL):
+		code = StatusInval
+	case errors.Is(err, os.ErrNotExist):
+		code = StatusNotSupported
+	case errors.Is(err, os.ErrPermission):
+		code = StatusNoPerm
+	}
+	return &StatusError{Op: fmt.Sprintf("%s: %v", op, err), Code: code}
+}
+
+func (s *SysfsBackend) Init() error {
+	count, err := s.GetGPUCount()
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return &StatusError{Op: fmt.Sprintf("sysfs lookup under %s", s.root), Code: StatusNotFound}
+	}
+	return nil
+}
+
+func (s *SysfsBackend) Shutdown() error {
+	s.Lock()
+	defer s.Unlock()
+	s.devices = nil
+	return nil
+}
+
+// GetGPUCount lists the drm cards exposing partition attributes, partitions
+// of a GPU in DPX/QPX/CPX mode show up as extra cards and are skipped
+func (s *SysfsBackend) GetGPUCount() (int, error) {
+	s.Lock()
+	defer s.Unlock()
+	entries, err := os.ReadDir(filepath.Join(s.root, "class", "drm"))
+	if err != nil {
+		return 0, sysfsStatus("read drm class", err)
+	}
+
+	type card struct {
+		idx    int
+		device string
+	}
+	cards := []card{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		idx, ok := cardIndex(entry.Name())
+		if !ok {
+			continue
+		}
+		device := filepath.Join(s.root, "class", "drm", entry.Name(), "device")
+		if _, err := os.Stat(filepath.Join(device, currentComputePartitionFile)); err != nil {
+			continue
+		}
+		resolved, err := filepath.EvalSymlinks(device)
+		if err != nil {
+			continue
+		}
+		if seen[resolved] {
+			continue
+		}
+		seen[resolved] = true
+		cards = append(cards, card{idx: idx, device: device})
+	}
+	sort.Slice(cards, func(i, j int) bool { return cards[i].idx < cards[j].idx })
+
+	s.devices = make([]string, len(cards))
+	for i, c := range cards {
+		s.devices[i] = c.device
+	}
+	return len(s.devices), nil
+}
+
+func (s *SysfsBackend) attrPath(op string, gpuID int, attr string) (string, error) {
+	s.Lock()
+	defer s.Unlock()
+	if gpuID < 0 || gpuID >= len(s.devices) {
+		return "", &StatusError{Op: fmt.Sprintf("%s gpu %d", op, gpuID), Code: StatusNotFound}
+	}
+	return filepath.Join(s.devices[gpuID], attr), nil
+}
+
+func (s *SysfsBackend) readAttr(op string, gpuID int, attr string) (string, error) {
+	path, err := s.attrPath(op, gpuID, attr)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", sysfsStatus(op, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// writeAttr validates the mode against the available_* attribute before
+// writing it, the driver rejects unknown modes with EINVAL anyway
+func (s *SysfsBackend) writeAttr(op string, gpuID int, attr, availableAttr, value string) error {
+	available, err := s.readAttr(op, gpuID, availableAttr)
+	if err != nil {
+		return err
+	}
+	if !containsMode(available, value) {
+		return &StatusError{Op: fmt.Sprintf("%s %s not in [%s]", op, value, available), Code: StatusInval}
+	}
+	path, err := s.attrPath(op, gpuID, attr)
+	if err != nil {
+		return err
+	}
+	return sysfsStatus(op, os.WriteFile(path, []byte(value), 0644))
+}
+
+func containsMode(list, mode string) bool {
+	for _, m := range splitModes(list) {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// GetProcessorCount derives the partition count from the compute mode and
+// the GPU model, the sysfs attributes do not link a partition card to its
+// parent GPU
+func (s *SysfsBackend) GetProcessorCount(gpuID int) (int, error) {
+	compute, err := s.GetComputePartition(gpuID)
+	if err != nil {
+		return 0, err
+	}
+	info, _ := s.GetASICInfo(gpuID)
+	if count, ok := PartitionCount(ASICModel(info), compute); ok {
+		return count, nil
+	}
+	return 1, nil
+}
+
+func (s *SysfsBackend) GetProcessorType(gpuID int) (ProcessorType, error) {
+	if _, err := s.attrPath("get processor type", gpuID, ""); err != nil {
+		return ProcessorTypeUnknown, err
+	}
+	// only amdgpu exposes the partition attributes used for enumeration
+	return ProcessorTypeAMDGPU, nil
+}
+
+func (s *SysfsBackend) GetComputePartition(gpuID int) (string, error) {
+	return s.readAttr("get compute partition", gpuID, currentComputePartitionFile)
+}
+
+func (s *SysfsBackend) GetMemoryPartition(gpuID int) (string, error) {
+	return s.readAttr("get memory partition", gpuID, currentMemoryPartitionFile)
+}
+
+func (s *SysfsBackend) SetComputePartition(gpuID int, partition string) error {
+	return s.writeAttr("set compute partition", gpuID, currentComputePartitionFile, availableComputePartitionFile, partition)
+}
+
+func (s *SysfsBackend) SetMemoryPartition(gpuID int, partition string) error {
+	return s.writeAttr("set memory partition", gpuID, currentMemoryPartitionFile, availableMemoryPartitionFile, partition)
+}
+
+func (s *SysfsBackend) GetPartitionCapabilities(gpuID int) (PartitionCapabilities, error) {
+	caps := PartitionCapabilities{}
+	compute, err := s.readAttr("get compute partition capabilities", gpuID, availableComputePartitionFile)
+	if err != nil {
+		return caps, err
+	}
+	memory, err := s.readAttr("get memory partition capabilities", gpuID, availableMemoryPartitionFile)
+	if err != nil {
+		return caps, err
+	}
+	caps.ComputePartitions = splitModes(compute)
+	caps.MemoryPartitions = splitModes(memory)
+	return caps, nil
+}
+
+// GetASICInfo reads the PCI device id and the product name from the FRU,
+// the product name is not exposed by all boards
+func (s *SysfsBackend) GetASICInfo(gpuID int) (ASICInfo, error) {
+	info := ASICInfo{}
+	device, err := s.readAttr("get device id", gpuID, deviceIDFile)
+	if err != nil {
+		return info, err
+	}
+	info.DeviceID, err = strconv.ParseUint(device, 0, 64)
+	if err != nil {
+		return info, &StatusError{Op: fmt.Sprintf("parse device id %q", device), Code: StatusUnexpectedData}
+	}
+	info.MarketName, _ = s.readAttr("get product name", gpuID, productNameFile)
+	return info, nil
+}
+
+// accelerator partition profiles are not exposed through sysfs
+func (s *SysfsBackend) GetAcceleratorProfiles(gpuID int) ([]AcceleratorProfile, error) {
+	return nil, &StatusError{Op: "sysfs get accelerator profiles", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) GetAcceleratorProfileIndex(gpuID int) (int, error) {
+	return 0, &StatusError{Op: "sysfs get accelerator profile", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) SetAcceleratorProfile(gpuID int, profileIndex int) error {
+	return &StatusError{Op: "sysfs set accelerator profile", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) GetPartitionMemory(gpuID int) ([]uint64, error) {
+	return nil, &StatusError{Op: "sysfs get partition memory", Code: StatusNotSupported}
+}
+
+// hwmonAttr returns the path of an attribute of the hwmon device of the GPU
+func (s *SysfsBackend) hwmonAttr(op string, gpuID int, attr string) (string, error) {
+	dir, err := s.attrPath(op, gpuID, "hwmon")
+	if err != nil {
+		return "", err
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, "hwmon*", attr))
+	if len(matches) == 0 {
+		return "", &StatusError{Op: fmt.Sprintf("%s: no %s under %s", op, attr, dir), Code: StatusNotSupported}
+	}
+	return matches[0], nil
+}
+
+func (s *SysfsBackend) readHwmonValue(op string, gpuID int, attr string) (uint64, error) {
+	path, err := s.hwmonAttr(op, gpuID, attr)
+	if err != nil {
+		return 0, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, sysfsStatus(op, err)
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, &StatusError{Op: fmt.Sprintf("%s: parse %s", op, path), Code: StatusUnexpectedData}
+	}
+	return value, nil
+}
+
+// GetPowerCapInfo reads the power1_cap* hwmon attributes, in microwatts
+func (s *SysfsBackend) GetPowerCapInfo(gpuID int) (PowerCapInfo, error) {
+	op := "get power cap"
+	info := PowerCapInfo{}
+	for _, v := range []struct {
+		attr  string
+		value *uint64
+	}{
+		{powerCapFile, &info.PowerCap},
+		{powerCapDefaultFile, &info.DefaultPowerCap},
+		{powerCapMinFile, &info.MinPowerCap},
+		{powerCapMaxFile, &info.MaxPowerCap},
+	} {
+		value, err := s.readHwmonValue(op, gpuID, v.attr)
+		if err != nil {
+			return info, err
+		}
+		*v.value = value
+	}
+	return info, nil
+}
+
+func (s *SysfsBackend) SetPowerCap(gpuID int, powerCap uint64) error {
+	op := "set power cap"
+	path, err := s.hwmonAttr(op, gpuID, powerCapFile)
+	if err != nil {
+		return err
+	}
+	return sysfsStatus(op, os.WriteFile(path, []byte(strconv.FormatUint(powerCap, 10)), 0644))
+}
+
+// sysfsPerfLevels maps the performance levels to their
+// power_dpm_force_performance_level names
+var sysfsPerfLevels = map[string]string{
+	"AUTO":            "auto",
+	"LOW":             "low",
+	"HIGH":            "high",
+	"MANUAL":          "manual",
+	"STABLE_STD":      "profile_standard",
+	"STABLE_PEAK":     "profile_peak",
+	"STABLE_MIN_MCLK": "profile_min_mclk",
+	"STABLE_MIN_SCLK": "profile_min_sclk",
+	"DETERMINISM":     "perf_determinism",
+}
+
+// clock limits are only exposed through the pp_od_clk_voltage table, which
+// is not parsed here
+func (s *SysfsBackend) GetClockInfo(gpuID int, clock string) (ClockInfo, error) {
+	return ClockInfo{}, &StatusError{Op: "sysfs get clock info", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) SetClockRange(gpuID int, clock string, clockRange ClockRange) error {
+	return &StatusError{Op: "sysfs set clock range", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) GetPerfLevel(gpuID int) (string, error) {
+	value, err := s.readAttr("get performance level", gpuID, perfLevelFile)
+	if err != nil {
+		return "", err
+	}
+	for level, name := range sysfsPerfLevels {
+		if name == value {
+			return level, nil
+		}
+	}
+	return "UNKNOWN", nil
+}
+
+func (s *SysfsBackend) SetPerfLevel(gpuID int, level string) error {
+	op := "set performance level"
+	name, ok := sysfsPerfLevels[level]
+	if !ok || level == PerfLevelDeterminism {
+		return &StatusError{Op: fmt.Sprintf("%s %s", op, level), Code: StatusInval}
+	}
+	path, err := s.attrPath(op, gpuID, perfLevelFile)
+	if err != nil {
+		return err
+	}
+	return sysfsStatus(op, os.WriteFile(path, []byte(name), 0644))
+}
+
+func (s *SysfsBackend) SetPerfDeterminismMode(gpuID int, gfxClockMax uint64) error {
+	return &StatusError{Op: "sysfs set performance determinism mode", Code: StatusNotSupported}
+}
+
+// power profiles are only exposed through the pp_power_profile_mode table,
+// which is not parsed here
+func (s *SysfsBackend) GetPowerProfile(gpuID int) (PowerProfileInfo, error) {
+	return PowerProfileInfo{}, &StatusError{Op: "sysfs get power profile", Code: StatusNotSupported}
+}
+
+func (s *SysfsBackend) SetPowerProfile(gpuID int, profile string) error {
+	return &StatusError{Op: "sysfs set power profile", Code: StatusNotSupported}
+}
+
+// GetProcessIsolation reads enforce_isolation, which holds one flag per
+// partition of the GPU
+func (s *SysfsBackend) GetProcessIsolation(gpuID int) (bool, error) {
+	value, err := s.readAttr("get process isolation", gpuID, enforceIsolationFile)
+	if err != nil {
+		return false, err
+	}
+	flags := strings.Fields(value)
+	if len(flags) == 0 {
+		return false, &StatusError{Op: "parse process isolation", Code: StatusUnexpectedData}
+	}
+	for _, flag := range flags {
+		if flag == "0" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *SysfsBackend) SetProcessIsolation(gpuID int, enabled bool) error {
+	op := "set process isolation"
+	value, err := s.readAttr(op, gpuID, enforceIsolationFile)
+	if err != nil {
+		return err
+	}
+	flag := "0"
+	if enabled {
+		flag = "1"
+	}
+	flags := make([]string, len(strings.Fields(value)))
+	for i := range flags {
+		flags[i] = flag
+	}
+	path, err := s.attrPath(op, gpuID, enforceIsolationFile)
+	if err != nil {
+		return err
+	}
+	return sysfsStatus(op, os.WriteFile(path, []byte(strings.Join(flags, " ")), 0644))
+}
+
+func (s *SysfsBackend) GetProcesses(gpuID int) ([]GPUProcess, error) {
+	return nil, &StatusError{Op: "sysfs get processes", Code: StatusNotSupported}
+}
+
+// GetPCIAddress returns the name of the PCI device directory of the GPU
+func (s *SysfsBackend) GetPCIAddress(gpuID int) (string, error) {
+	device, err := s.attrPath("get pci address", gpuID, "")
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(device)
+	if err != nil {
+		return "", sysfsStatus("get pci address", err)
+	}
+	return filepath.Base(resolved), nil
+}
@@ -0,0 +1,141 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newFakeSysfs creates a drm class tree with numGPUs partitionable cards
+// and an extra partition card linked to the first GPU
+func newFakeSysfs(t *testing.T, numGPUs int) string {
+	root := t.TempDir()
+	drm := filepath.Join(root, "class", "drm")
+	for i := 0; i < numGPUs; i++ {
+		device := filepath.Join(root, "devices", "pci", fmt.Sprintf("0000:%02x:00.0", i+1))
+		assert.NoError(t, os.MkdirAll(device, 0755))
+		files := map[string]string{
+			currentComputePartitionFile:   "SPX\n",
+			availableComputePartitionFile: "SPX, DPX, QPX, CPX\n",
+			currentMemoryPartitionFile:    "NPS1\n",
+			availableMemoryPartitionFile:  "NPS1, NPS4\n",
+			deviceIDFile:                  "0x74a1\n",
+			enforceIsolationFile:          "0 0\n",
+		}
+		for name, content := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(device, name), []byte(content), 0644))
+		}
+		card := filepath.Join(drm, fmt.Sprintf("card%d", i))
+		assert.NoError(t, os.MkdirAll(card, 0755))
+		assert.NoError(t, os.Symlink(device, filepath.Join(card, "device")))
+	}
+	partitionCard := filepath.Join(drm, "card9")
+	assert.NoError(t, os.MkdirAll(partitionCard, 0755))
+	first, _ := os.Readlink(filepath.Join(drm, "card0", "device"))
+	assert.NoError(t, os.Symlink(first, filepath.Join(partitionCard, "device")))
+	assert.NoError(t, os.MkdirAll(filepath.Join(drm, "renderD128"), 0755))
+	return root
+}
+
+func TestSysfsBackend(t *testing.T) {
+	sysfs := NewSysfsBackend(newFakeSysfs(t, 2))
+	assert.NoError(t, sysfs.Init())
+
+	count, err := sysfs.GetGPUCount()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	compute, err := sysfs.GetComputePartition(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "SPX", compute)
+
+	assert.NoError(t, sysfs.SetComputePartition(1, "CPX"))
+	compute, _ = sysfs.GetComputePartition(1)
+	assert.Equal(t, "CPX", compute)
+	partitions, _ := sysfs.GetProcessorCount(1)
+	assert.Equal(t, 8, partitions)
+
+	assert.NoError(t, sysfs.SetMemoryPartition(0, "NPS4"))
+	memory, _ := sysfs.GetMemoryPartition(0)
+	assert.Equal(t, "NPS4", memory)
+
+	caps, err := sysfs.GetPartitionCapabilities(0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"SPX", "DPX", "QPX", "CPX"}, caps.ComputePartitions)
+	assert.Equal(t, []string{"NPS1", "NPS4"}, caps.MemoryPartitions)
+	info, err := sysfs.GetASICInfo(0)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0x74a1), info.DeviceID)
+	assert.Equal(t, "", info.MarketName)
+
+	isolation, err := sysfs.GetProcessIsolation(0)
+	assert.NoError(t, err)
+	assert.False(t, isolation)
+	assert.NoError(t, sysfs.SetProcessIsolation(0, true))
+	isolation, _ = sysfs.GetProcessIsolation(0)
+	assert.True(t, isolation)
+
+	assert.Equal(t, StatusInval, StatusCode(sysfs.SetMemoryPartition(0, "NPS2")))
+	assert.Equal(t, StatusNotFound, StatusCode(sysfs.SetMemoryPartition(2, "NPS1")))
+}
+
+func TestSysfsBackendNoGPUs(t *testing.T) {
+	sysfs := NewSysfsBackend(t.TempDir())
+	assert.Error(t, sysfs.Init())
+}
+
+// addFakeKFDNode adds a GPU node to the KFD topology of a fake sysfs tree
+func addFakeKFDNode(t *testing.T, root string, node int, gpuID int, properties string) {
+	dir := filepath.Join(root, "class", "kfd", "kfd", "topology", "nodes", fmt.Sprint(node))
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "gpu_id"), []byte(fmt.Sprintf("%d\n", gpuID)), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "properties"), []byte(properties), 0644))
+}
+
+func TestReadTopology(t *testing.T) {
+	root := newFakeSysfs(t, 2)
+	sysfs := NewSysfsBackend(root)
+	assert.NoError(t, sysfs.Init())
+	address, err := sysfs.GetPCIAddress(1)
+	assert.NoError(t, err)
+	assert.Equal(t, "0000:02:00.0", address)
+
+	// CPU node
+	addFakeKFDNode(t, root, 0, 0, "cpu_cores_count 96\nsimd_count 0\n")
+	// GPU 0 in DPX, the render node of the second partition is missing and
+	// the partition id is in bits 28-31 of the location
+	addFakeKFDNode(t, root, 1, 51966, "simd_count 152\ndrm_render_minor 128\nlocation_id 256\ndomain 0\n")
+	addFakeKFDNode(t, root, 2, 51967, "simd_count 152\ndrm_render_minor 129\nlocation_id 268435712\ndomain 0\n")
+	// GPU 1 in SPX
+	addFakeKFDNode(t, root, 3, 12345, "drm_render_minor 128\nlocation_id 512\ndomain 0\n")
+
+	topology, err := ReadTopology(root)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]SocketTopology{
+		"0000:01:00.0": {KFDNodes: 2, RenderNodes: 1},
+		"0000:02:00.0": {KFDNodes: 1, RenderNodes: 1},
+	}, topology)
+
+	_, err = ReadTopology(t.TempDir())
+	assert.Error(t, err)
+	assert.Equal(t, "0001:c1:1f.7", formatPCIAddress(0x1c1ff))
+}
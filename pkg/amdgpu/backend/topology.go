@@ -0,0 +1,85 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// SocketTopology counts the partitions the amdgpu driver exposes for a GPU
+// socket
+type SocketTopology struct {
+	// GPU nodes of the KFD topology, one per partition
+	KFDNodes int
+	// render nodes of the partitions present under class/drm
+	RenderNodes int
+}
+
+// ReadTopology reads the KFD topology under <root>/class/kfd/kfd/topology
+// and returns the partitions of each GPU socket keyed by PCI address. The
+// partitions of a socket share its PCI location once their partition id is
+// masked out, CPU nodes are skipped.
+func ReadTopology(root string) (map[string]SocketTopology, error) {
+	nodesDir := filepath.Join(root, "class", "kfd", "kfd", "topology", "nodes")
+	nodes, err := os.ReadDir(nodesDir)
+	if err != nil {
+		return nil, err
+	}
+	topology := map[string]SocketTopology{}
+	for _, node := range nodes {
+		dir := filepath.Join(nodesDir, node.Name())
+		gpuID, err := os.ReadFile(filepath.Join(dir, "gpu_id"))
+		if err != nil || strings.TrimSpace(string(gpuID)) == "0" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, "properties"))
+		if err != nil {
+			return nil, fmt.Errorf("KFD node %v: %v", node.Name(), err)
+		}
+		properties := parseProperties(string(data))
+		// amdgpu ORs the partition id into bits 28-31 of the location
+		address := formatPCIAddress(properties["domain"]<<16 | properties["location_id"]&0xffff)
+		socket := topology[address]
+		socket.KFDNodes++
+		renderNode := fmt.Sprintf("renderD%d", properties["drm_render_minor"])
+		if _, err := os.Stat(filepath.Join(root, "class", "drm", renderNode)); err == nil {
+			socket.RenderNodes++
+		}
+		topology[address] = socket
+	}
+	return topology, nil
+}
+
+// parseProperties parses the "name value" lines of a KFD node properties
+// file, lines without a numeric value are skipped
+func parseProperties(data string) map[string]uint64 {
+	properties := map[string]uint64{}
+	for _, line := range strings.Split(data, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		if value, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			properties[fields[0]] = value
+		}
+	}
+	return properties
+}
@@ -0,0 +1,157 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hostproc finds the processes of the node holding AMD GPU device
+// files open by scanning /proc
+package hostproc
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// environment variable pointing at the proc mount of the host, e.g. a
+	// hostPath mount of /proc or a fake tree in tests
+	ProcRootEnv     = "DCM_PROC_ROOT"
+	DefaultProcRoot = "/proc"
+)
+
+// container runtimes put the 64 hex digit container id in the cgroup path,
+// e.g. cri-containerd-<id>.scope, docker-<id>.scope or crio-<id>.scope
+var containerIDPattern = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// Holder is a process holding GPU device files open
+type Holder struct {
+	PID     int
+	Command string
+	// cgroup path of the process, the unified hierarchy on cgroup v2
+	Cgroup string
+	// empty when the process does not run in a container
+	ContainerID string
+	// device files held open, e.g. /dev/kfd
+	Devices []string
+}
+
+// ProcRootFromEnv returns the proc mount to scan
+func ProcRootFromEnv() string {
+	if root := os.Getenv(ProcRootEnv); root != "" {
+		return root
+	}
+	return DefaultProcRoot
+}
+
+// isGPUDevice reports whether path is the KFD or a DRM render node
+func isGPUDevice(path string) bool {
+	return path == "/dev/kfd" || strings.HasPrefix(path, "/dev/dri/renderD")
+}
+
+// Scan lists the processes under procRoot holding /dev/kfd or a render node
+// open, sorted by PID. The scanning process itself is skipped, processes
+// that exit or cannot be read during the scan are left out.
+func Scan(procRoot string) ([]Holder, error) {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	self := -1
+	if link, err := os.Readlink(filepath.Join(procRoot, "self")); err == nil {
+		self, _ = strconv.Atoi(link)
+	}
+	holders := []Holder{}
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+		devices := openDevices(procRoot, pid)
+		if len(devices) == 0 {
+			continue
+		}
+		holder := Describe(procRoot, pid)
+		holder.Devices = devices
+		holders = append(holders, holder)
+	}
+	sort.Slice(holders, func(i, j int) bool { return holders[i].PID < holders[j].PID })
+	return holders, nil
+}
+
+// openDevices returns the GPU device files a process holds open
+func openDevices(procRoot string, pid int) []string {
+	fdDir := filepath.Join(procRoot, strconv.Itoa(pid), "fd")
+	fds, err := os.ReadDir(fdDir)
+	if err != nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	devices := []string{}
+	for _, fd := range fds {
+		target, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !isGPUDevice(target) || seen[target] {
+			continue
+		}
+		seen[target] = true
+		devices = append(devices, target)
+	}
+	sort.Strings(devices)
+	return devices
+}
+
+// Describe returns the command, cgroup and container of a process, fields
+// that cannot be read are left empty
+func Describe(procRoot string, pid int) Holder {
+	holder := Holder{PID: pid}
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	if data, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		holder.Command = strings.TrimSpace(strings.ReplaceAll(string(data), "\x00", " "))
+	}
+	if holder.Command == "" {
+		if data, err := os.ReadFile(filepath.Join(dir, "comm")); err == nil {
+			holder.Command = strings.TrimSpace(string(data))
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+		holder.Cgroup = parseCgroup(string(data))
+		if ids := containerIDPattern.FindAllString(holder.Cgroup, -1); len(ids) != 0 {
+			holder.ContainerID = ids[len(ids)-1]
+		}
+	}
+	return holder
+}
+
+// parseCgroup picks the cgroup path of the unified hierarchy, or the first
+// hierarchy on cgroup v1
+func parseCgroup(data string) string {
+	first := ""
+	for _, line := range strings.Split(strings.TrimSpace(data), "\n") {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			return fields[2]
+		}
+		if first == "" {
+			first = fields[2]
+		}
+	}
+	return first
+}
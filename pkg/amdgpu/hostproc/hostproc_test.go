@@ -0,0 +1,110 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostproc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testContainerID = "3f2a6c1e9b8d7f605142a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8"
+
+// fakeProc describes a process of a fake proc tree
+type fakeProc struct {
+	cmdline string
+	comm    string
+	cgroup  string
+	fds     []string
+}
+
+// newFakeProc creates a proc tree with the given processes, the self link
+// points at PID 1
+func newFakeProc(t *testing.T, procs map[int]fakeProc) string {
+	root := t.TempDir()
+	for pid, p := range procs {
+		dir := filepath.Join(root, strconv.Itoa(pid))
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0755))
+		files := map[string]string{"cmdline": p.cmdline, "comm": p.comm, "cgroup": p.cgroup}
+		for name, content := range files {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+		}
+		for fd, target := range p.fds {
+			assert.NoError(t, os.Symlink(target, filepath.Join(dir, "fd", strconv.Itoa(fd))))
+		}
+	}
+	assert.NoError(t, os.Symlink("1", filepath.Join(root, "self")))
+	// entries other than PIDs are skipped
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "sys"), 0755))
+	return root
+}
+
+func TestScan(t *testing.T) {
+	root := newFakeProc(t, map[int]fakeProc{
+		// the scanning process
+		1: {cmdline: "device-config-manager\x00", fds: []string{"/dev/kfd"}},
+		4242: {
+			cmdline: "python\x00train.py\x00",
+			cgroup:  "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + testContainerID + ".scope\n",
+			fds:     []string{"/dev/null", "/dev/kfd", "/dev/dri/renderD128", "/dev/kfd"},
+		},
+		77: {
+			comm:   "rocm-smi-daemon\n",
+			cgroup: "12:devices:/system.slice/rocm.service\n11:memory:/system.slice/rocm.service\n",
+			fds:    []string{"/dev/dri/renderD129"},
+		},
+		99: {cmdline: "bash\x00", cgroup: "0::/user.slice\n", fds: []string{"/dev/dri/card0", "socket:[1234]"}},
+	})
+
+	holders, err := Scan(root)
+	assert.NoError(t, err)
+	assert.Equal(t, []Holder{
+		{PID: 77, Command: "rocm-smi-daemon", Cgroup: "/system.slice/rocm.service", Devices: []string{"/dev/dri/renderD129"}},
+		{
+			PID:         4242,
+			Command:     "python train.py",
+			Cgroup:      "/kubepods.slice/kubepods-pod1.slice/cri-containerd-" + testContainerID + ".scope",
+			ContainerID: testContainerID,
+			Devices:     []string{"/dev/dri/renderD128", "/dev/kfd"},
+		},
+	}, holders)
+
+	// a process amdsmi reports but that exited meanwhile
+	assert.Equal(t, Holder{PID: 5000}, Describe(root, 5000))
+
+	_, err = Scan(filepath.Join(root, "missing"))
+	assert.Error(t, err)
+}
+
+func TestParseCgroup(t *testing.T) {
+	v1 := "12:pids:/docker/" + testContainerID + "\n1:name=systemd:/docker/" + testContainerID + "\n"
+	assert.Equal(t, "/docker/"+testContainerID, parseCgroup(v1))
+	hybrid := "1:name=systemd:/user.slice\n0::/system.slice/docker-" + testContainerID + ".scope\n"
+	assert.Equal(t, "/system.slice/docker-"+testContainerID+".scope", parseCgroup(hybrid))
+	assert.Equal(t, "", parseCgroup(""))
+	assert.Equal(t, testContainerID, containerIDPattern.FindString(hybrid))
+}
+
+func TestProcRootFromEnv(t *testing.T) {
+	t.Setenv(ProcRootEnv, "")
+	assert.Equal(t, DefaultProcRoot, ProcRootFromEnv())
+	t.Setenv(ProcRootEnv, "/host-proc")
+	assert.Equal(t, "/host-proc", ProcRootFromEnv())
+}
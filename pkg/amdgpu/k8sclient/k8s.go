@@ -26,7 +26,9 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
@@ -39,8 +41,18 @@ type K8sClient struct {
 	sync.Mutex
 	ctx       context.Context
 	clientset *kubernetes.Clientset
+	// result of the last API server probe of Ready
+	readyErr error
+	readyAt  time.Time
 }
 
+const (
+	// bound of the API server probe of Ready
+	readyTimeout = 2 * time.Second
+	// time the result of the API server probe is reused
+	readyCacheDuration = 10 * time.Second
+)
+
 func (k *K8sClient) init() error {
 	k.Lock()
 	defer k.Unlock()
@@ -74,6 +86,33 @@ func (k *K8sClient) reConnect() error {
 	return nil
 }
 
+// Ready reports whether the API server can be reached, the result of the
+// probe is cached so frequent health checks do not load the API server
+func (k *K8sClient) Ready() error {
+	if err := k.reConnect(); err != nil {
+		return err
+	}
+	k.Lock()
+	if !k.readyAt.IsZero() && time.Since(k.readyAt) < readyCacheDuration {
+		defer k.Unlock()
+		return k.readyErr
+	}
+	clientset := k.clientset
+	k.Unlock()
+
+	ctx, cancel := context.WithTimeout(k.ctx, readyTimeout)
+	defer cancel()
+	err := clientset.Discovery().RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+	if err != nil {
+		err = fmt.Errorf("k8s API server not reachable: %w", err)
+	}
+	k.Lock()
+	defer k.Unlock()
+	k.readyErr = err
+	k.readyAt = time.Now()
+	return err
+}
+
 func IsKMMDriverEnabled() bool {
 	if os.Getenv("KMM_DRIVER_ENABLED") != "" {
 		if strings.ToLower(os.Getenv("KMM_DRIVER_ENABLED")) == "true" {
@@ -230,32 +269,67 @@ func (k *K8sClient) GetDaemonSets() []string {
 	return daemonsetlist
 }
 
-func (k *K8sClient) GetPods(nodeName string) []string {
+func (k *K8sClient) AddNodeLabel(nodeName string, key string, value string) error {
 	k.reConnect()
 	k.Lock()
 	defer k.Unlock()
 	ctx, cancel := context.WithCancel(k.ctx)
 	defer cancel()
 
-	podNames := []string{}
+	retries := 10
+	var err error
+	var node *v1.Node
+
+	for i := range retries {
+		node, err = k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err == nil {
+			break
+		}
+
+		log.Printf("k8s get node API failed (attempt %d/%d): %v", i+1, retries, err)
+		time.Sleep(10 * time.Second)
+	}
 
-	// List all pods across all namespaces
-	pods, err := k.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
 	if err != nil {
-		log.Printf("Failed to list pods: %v", err)
-		return podNames
+		return err
 	}
 
-	for _, pod := range pods.Items {
-		if pod.Spec.NodeName == nodeName {
-			podNames = append(podNames, pod.Name)
+	node.Labels[key] = value
+
+	for i := range retries {
+		_, err = k.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		if err == nil {
+			break
 		}
+
+		log.Printf("k8s update node API failed (attempt %d/%d): %v", i+1, retries, err)
+		time.Sleep(10 * time.Second)
+	}
+
+	if err != nil {
+		return err
 	}
 
-	return podNames
+	log.Printf("Gpu-config-profile-state label added successfully")
+	return nil
 }
 
-func (k *K8sClient) AddNodeLabel(nodeName string, key string, value string) error {
+func (k *K8sClient) GetNodeAnnotations(nodeName string) (map[string]string, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	node, err := k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		log.Printf("k8s internal node get failed %v", err)
+		return make(map[string]string), err
+	}
+	return node.Annotations, nil
+}
+
+func (k *K8sClient) AddNodeAnnotation(nodeName string, key string, value string) error {
 	k.reConnect()
 	k.Lock()
 	defer k.Unlock()
@@ -268,11 +342,21 @@ func (k *K8sClient) AddNodeLabel(nodeName string, key string, value string) erro
 
 	for i := range retries {
 		node, err = k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("k8s get node API failed (attempt %d/%d): %v", i+1, retries, err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[key] = value
+		_, err = k.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
 		if err == nil {
 			break
 		}
-
-		log.Printf("k8s get node API failed (attempt %d/%d): %v", i+1, retries, err)
+		log.Printf("k8s update node API failed (attempt %d/%d): %v", i+1, retries, err)
 		time.Sleep(10 * time.Second)
 	}
 
@@ -280,22 +364,186 @@ func (k *K8sClient) AddNodeLabel(nodeName string, key string, value string) erro
 		return err
 	}
 
-	node.Labels[key] = value
+	log.Printf("Node annotation %v added successfully", key)
+	return nil
+}
+
+// GetNodePods lists the pods scheduled on the node
+func (k *K8sClient) GetNodePods(nodeName string) ([]v1.Pod, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	pods, err := k.clientset.CoreV1().Pods(metav1.NamespaceAll).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", nodeName).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// GetNodeAllocatable returns the resources of the node available to pods
+func (k *K8sClient) GetNodeAllocatable(nodeName string) (v1.ResourceList, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	node, err := k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return node.Status.Allocatable, nil
+}
+
+// DeletePod deletes a pod, its controller recreates it
+func (k *K8sClient) DeletePod(namespace string, name string) error {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	return k.clientset.CoreV1().Pods(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+}
+
+// SetNodeUnschedulable cordons or uncordons the node, it returns whether the
+// node was unschedulable before
+func (k *K8sClient) SetNodeUnschedulable(nodeName string, unschedulable bool) (bool, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	retries := 10
+	var err error
+	var node *v1.Node
+	var wasUnschedulable bool
 
 	for i := range retries {
+		node, err = k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("k8s get node API failed (attempt %d/%d): %v", i+1, retries, err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		wasUnschedulable = node.Spec.Unschedulable
+		if wasUnschedulable == unschedulable {
+			return wasUnschedulable, nil
+		}
+		node.Spec.Unschedulable = unschedulable
 		_, err = k.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
 		if err == nil {
 			break
 		}
-
 		log.Printf("k8s update node API failed (attempt %d/%d): %v", i+1, retries, err)
 		time.Sleep(10 * time.Second)
 	}
 
 	if err != nil {
-		return err
+		return wasUnschedulable, err
 	}
+	log.Printf("Node %v unschedulable set to %v", nodeName, unschedulable)
+	return wasUnschedulable, nil
+}
 
-	log.Printf("Gpu-config-profile-state label added successfully")
-	return nil
+// EvictPod evicts a pod through the Eviction API so PodDisruptionBudgets are
+// respected, a TooManyRequests error means the budget does not allow it yet
+func (k *K8sClient) EvictPod(namespace string, name string) error {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	return k.clientset.PolicyV1().Evictions(namespace).Evict(ctx, &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+	})
+}
+
+// GetNodeTaints returns the taints of the node
+func (k *K8sClient) GetNodeTaints(nodeName string) ([]v1.Taint, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	node, err := k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return node.Spec.Taints, nil
+}
+
+// AddNodeTaint taints the node, it returns false when the node already had
+// a taint with the same key and effect
+func (k *K8sClient) AddNodeTaint(nodeName string, taint v1.Taint) (bool, error) {
+	return k.updateNodeTaints(nodeName, func(taints []v1.Taint) ([]v1.Taint, bool) {
+		for _, t := range taints {
+			if t.MatchTaint(&taint) {
+				return taints, false
+			}
+		}
+		return append(taints, taint), true
+	})
+}
+
+// RemoveNodeTaint removes the taints with the key and effect of taint
+func (k *K8sClient) RemoveNodeTaint(nodeName string, taint v1.Taint) error {
+	_, err := k.updateNodeTaints(nodeName, func(taints []v1.Taint) ([]v1.Taint, bool) {
+		kept := []v1.Taint{}
+		for _, t := range taints {
+			if !t.MatchTaint(&taint) {
+				kept = append(kept, t)
+			}
+		}
+		return kept, len(kept) != len(taints)
+	})
+	return err
+}
+
+// updateNodeTaints replaces the taints of the node with the ones returned by
+// update, the node is only updated when update reports a change
+func (k *K8sClient) updateNodeTaints(nodeName string, update func([]v1.Taint) ([]v1.Taint, bool)) (bool, error) {
+	k.reConnect()
+	k.Lock()
+	defer k.Unlock()
+	ctx, cancel := context.WithCancel(k.ctx)
+	defer cancel()
+
+	retries := 10
+	var err error
+	var node *v1.Node
+	changed := false
+
+	for i := range retries {
+		node, err = k.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			log.Printf("k8s get node API failed (attempt %d/%d): %v", i+1, retries, err)
+			time.Sleep(10 * time.Second)
+			continue
+		}
+		node.Spec.Taints, changed = update(node.Spec.Taints)
+		if !changed {
+			return false, nil
+		}
+		_, err = k.clientset.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+		if err == nil {
+			break
+		}
+		log.Printf("k8s update node API failed (attempt %d/%d): %v", i+1, retries, err)
+		time.Sleep(10 * time.Second)
+	}
+
+	if err != nil {
+		return false, err
+	}
+	log.Printf("Node %v taints updated to %v", nodeName, node.Spec.Taints)
+	return true, nil
 }
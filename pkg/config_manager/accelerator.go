@@ -0,0 +1,138 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"fmt"
+	"log"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+func toAcceleratorProfiles(profiles []backend.AcceleratorProfile) []types.AcceleratorProfile {
+	result := make([]types.AcceleratorProfile, len(profiles))
+	for i, p := range profiles {
+		result[i] = types.AcceleratorProfile{
+			Index:            p.Index,
+			Type:             p.Type,
+			NumPartitions:    p.NumPartitions,
+			MemoryPartitions: p.MemoryPartitions,
+			Resources:        p.Resources,
+		}
+	}
+	return result
+}
+
+// resolveAcceleratorProfile finds the accelerator profile referenced by a
+// partition config, by type the profile supporting its memory type is used
+func resolveAcceleratorProfile(profiles []types.AcceleratorProfile, p *partition_pb.ProfileConfig) (types.AcceleratorProfile, error) {
+	ref := p.AcceleratorProfile
+	if len(profiles) == 0 {
+		return types.AcceleratorProfile{}, fmt.Errorf("accelerator partition profiles are not supported")
+	}
+	if ref.Type != "" {
+		found := false
+		for _, profile := range profiles {
+			if profile.Type != ref.Type {
+				continue
+			}
+			found = true
+			if ValidateList(p.MemoryPartition, profile.MemoryPartitions) {
+				return profile, nil
+			}
+		}
+		if !found {
+			return types.AcceleratorProfile{}, fmt.Errorf("no accelerator profile of type %v", ref.Type)
+		}
+		return types.AcceleratorProfile{}, fmt.Errorf("no accelerator profile of type %v supports memory type %v", ref.Type, p.MemoryPartition)
+	}
+
+	indices := []int{}
+	for _, profile := range profiles {
+		indices = append(indices, profile.Index)
+		if profile.Index != int(ref.Index) {
+			continue
+		}
+		if profile.Type != p.ComputePartition {
+			return profile, fmt.Errorf("accelerator profile %d is of type %v, not %v", profile.Index, profile.Type, p.ComputePartition)
+		}
+		if !ValidateList(p.MemoryPartition, profile.MemoryPartitions) {
+			return profile, fmt.Errorf("accelerator profile %d supports memory types %v, not %v", profile.Index, profile.MemoryPartitions, p.MemoryPartition)
+		}
+		return profile, nil
+	}
+	return types.AcceleratorProfile{}, fmt.Errorf("accelerator profile index %d not found, available indices are %v", ref.Index, indices)
+}
+
+// applyAcceleratorProfile switches the GPU to the accelerator profile unless
+// it is already in use
+func applyAcceleratorProfile(gpu backend.GPUBackend, gpuID int, profileIndex int) error {
+	current, err := gpu.GetAcceleratorProfileIndex(gpuID)
+	if err == nil && current == profileIndex {
+		log.Println("Existing and requested accelerator profile matching! Compute partition not required !!")
+		return nil
+	}
+	log.Printf("Triggering accelerator profile change from %v to %v !!", current, profileIndex)
+	if err := gpu.SetAcceleratorProfile(gpuID, profileIndex); err != nil {
+		return err
+	}
+	log.Println("Accelerator profile change successful !!")
+	return nil
+}
+
+// acceleratorProfileStatus reports the current accelerator profile of a GPU
+// and the resources of each partition
+func acceleratorProfileStatus(gpu backend.GPUBackend, gpuID int) *types.AcceleratorProfileStatus {
+	index, err := gpu.GetAcceleratorProfileIndex(gpuID)
+	if err != nil {
+		log_e.Errorf("GPU ID %v: failed to get the accelerator profile: %v", gpuID, err)
+		return nil
+	}
+	profiles, err := gpu.GetAcceleratorProfiles(gpuID)
+	if err != nil {
+		log_e.Errorf("GPU ID %v: failed to get the accelerator profiles: %v", gpuID, err)
+		return nil
+	}
+	status := &types.AcceleratorProfileStatus{Index: index, Partitions: []types.PartitionResources{}}
+	var resources map[string]int
+	numPartitions := 0
+	for _, p := range profiles {
+		if p.Index == index {
+			status.Type = p.Type
+			resources = p.Resources
+			numPartitions = p.NumPartitions
+		}
+	}
+	memory, err := gpu.GetPartitionMemory(gpuID)
+	if err != nil {
+		log_e.Errorf("GPU ID %v: failed to get the partition memory: %v", gpuID, err)
+	}
+	if len(memory) > numPartitions {
+		numPartitions = len(memory)
+	}
+	for i := range numPartitions {
+		partition := types.PartitionResources{PartitionID: i, Resources: resources}
+		if i < len(memory) {
+			partition.MemoryBytes = memory[i]
+		}
+		status.Partitions = append(status.Partitions, partition)
+	}
+	return status
+}
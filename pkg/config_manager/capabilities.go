@@ -0,0 +1,333 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+var (
+	capabilitiesMu sync.Mutex
+	// capabilities published last, used to update the node only on changes
+	lastCapabilities string
+)
+
+// discoverCapabilities queries the model of each GPU along with the partition
+// modes, power cap and clock ranges and power profiles it supports, GPUs whose
+// modes cannot be read are only checked against the static lists
+func discoverCapabilities(gpu backend.GPUBackend, totalGPUCount int) []types.GPUCapabilities {
+	caps := make([]types.GPUCapabilities, totalGPUCount)
+	for id := range totalGPUCount {
+		caps[id] = types.GPUCapabilities{GpuID: id, ComputePartitions: []string{}, MemoryPartitions: []string{}}
+		info, err := gpu.GetASICInfo(id)
+		if err != nil {
+			log_e.Warnf("GPU ID %v: failed to get the asic info: %v", id, err)
+		} else {
+			caps[id].Model = backend.ASICModel(info)
+			if caps[id].Model == "" {
+				log.Printf("GPU ID %v: no partition compatibility matrix for %q (device id 0x%x)", id, info.MarketName, info.DeviceID)
+			}
+		}
+		if info, err := gpu.GetPowerCapInfo(id); err == nil {
+			caps[id].MinPowerCapWatts = uint32(info.MinPowerCap / microWattsPerWatt)
+			caps[id].MaxPowerCapWatts = uint32(info.MaxPowerCap / microWattsPerWatt)
+		} else {
+			log.Printf("GPU ID %v: power cap range not known: %v", id, err)
+		}
+		for _, clock := range []string{backend.ClockGFX, backend.ClockMEM} {
+			info, err := gpu.GetClockInfo(id, clock)
+			if err != nil {
+				log.Printf("GPU ID %v: %v clock range not known: %v", id, clock, err)
+				continue
+			}
+			if caps[id].ClockLimits == nil {
+				caps[id].ClockLimits = map[string]types.ClockRange{}
+			}
+			caps[id].ClockLimits[clock] = types.ClockRange{MinMHz: uint32(info.Limits.Min), MaxMHz: uint32(info.Limits.Max)}
+		}
+		if info, err := gpu.GetPowerProfile(id); err == nil {
+			caps[id].PowerProfiles = info.Available
+		} else {
+			log.Printf("GPU ID %v: power profiles not known: %v", id, err)
+		}
+		c, err := gpu.GetPartitionCapabilities(id)
+		if err != nil {
+			log_e.Warnf("GPU ID %v: failed to discover the supported partition modes: %v", id, err)
+			continue
+		}
+		caps[id].ComputePartitions = c.ComputePartitions
+		caps[id].MemoryPartitions = c.MemoryPartitions
+		log.Printf("GPU ID %v (%v) supports compute partitions %v and memory partitions %v", id, caps[id].Model, c.ComputePartitions, c.MemoryPartitions)
+		profiles, err := gpu.GetAcceleratorProfiles(id)
+		if err != nil {
+			log.Printf("GPU ID %v: no accelerator partition profiles: %v", id, err)
+			continue
+		}
+		caps[id].AcceleratorProfiles = toAcceleratorProfiles(profiles)
+	}
+	return caps
+}
+
+// GetCapabilities returns the partition modes supported by every GPU
+func GetCapabilities() ([]types.GPUCapabilities, error) {
+	gpu, err := initGPUBackend()
+	if err != nil {
+		return nil, err
+	}
+	defer shutDownAMDSMI(gpu)
+	count, err := gpu.GetGPUCount()
+	if err != nil {
+		return nil, err
+	}
+	return discoverCapabilities(gpu, count), nil
+}
+
+// gpuIssue is a problem shared by the GPUs of a partition config
+type gpuIssue struct {
+	field   string
+	message string
+	ids     []int
+}
+
+// checkCompatibility returns the field and reason when the GPU model does not
+// allow the compute and memory partition combination
+func checkCompatibility(model, compute, memory string) (string, string) {
+	matrix, ok := globals.PartitionCompatibility[model]
+	if !ok {
+		return "", ""
+	}
+	computeTypes := []string{}
+	for _, c := range sortedKeys(matrix) {
+		if ValidateList(memory, matrix[c]) {
+			computeTypes = append(computeTypes, c)
+		}
+	}
+	if len(computeTypes) == 0 {
+		return "memoryPartition", fmt.Sprintf("memory type %v is not supported on %v", memory, model)
+	}
+	if _, ok := matrix[compute]; !ok {
+		return "computePartition", fmt.Sprintf("compute type %v is not supported on %v", compute, model)
+	}
+	if !ValidateList(memory, matrix[compute]) {
+		return "computePartition", fmt.Sprintf("compute type %v cannot be used with memory type %v on %v, compute types valid with %v are %v", compute, memory, model, memory, computeTypes)
+	}
+	return "", ""
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if computeOrder(keys[i]) != computeOrder(keys[j]) {
+			return computeOrder(keys[i]) < computeOrder(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// computeOrder sorts compute types from the least to the most partitions
+func computeOrder(compute string) int {
+	for i, c := range []string{"SPX", "DPX", "TPX", "QPX", "CPX"} {
+		if c == compute {
+			return i
+		}
+	}
+	return math.MaxInt
+}
+
+// checkCapabilities returns the partition configs of a profile requesting a
+// mode the GPUs they are assigned to do not support, or a combination their
+// model does not allow, profiles failing checkProfile are skipped as their
+// GPU assignment is not known
+func checkCapabilities(name string, profile *partition_pb.GPUConfigProfile, totalGPUCount int, caps []types.GPUCapabilities) []types.ValidationIssue {
+	issues := []types.ValidationIssue{}
+	if len(caps) == 0 || HasErrors(checkProfile(name, profile, totalGPUCount)) {
+		return issues
+	}
+	skipped := []uint32{}
+	if profile.Filters != nil {
+		skipped = profile.Filters.Id
+	}
+	gpuIDs := createGPUIDList(skipped, totalGPUCount)
+	idx := 0
+	for i, p := range profile.Profiles {
+		path := fmt.Sprintf("%s.%s.profiles[%d]", profilesPath, name, i)
+		found := []*gpuIssue{}
+		add := func(id int, field, message string) {
+			for _, f := range found {
+				if f.field == field && f.message == message {
+					f.ids = append(f.ids, id)
+					return
+				}
+			}
+			found = append(found, &gpuIssue{field: field, message: message, ids: []int{id}})
+		}
+		for range p.NumGPUsAssigned {
+			id := gpuIDs[idx]
+			idx++
+			if id >= len(caps) {
+				continue
+			}
+			c := caps[id]
+			switch {
+			case len(c.ComputePartitions) > 0 && !ValidateList(p.ComputePartition, c.ComputePartitions):
+				add(id, "computePartition", fmt.Sprintf("compute type %v is not supported, supported types are %v", p.ComputePartition, c.ComputePartitions))
+			case len(c.MemoryPartitions) > 0 && !ValidateList(p.MemoryPartition, c.MemoryPartitions):
+				add(id, "memoryPartition", fmt.Sprintf("memory type %v is not supported, supported types are %v", p.MemoryPartition, c.MemoryPartitions))
+			default:
+				if field, reason := checkCompatibility(c.Model, p.ComputePartition, p.MemoryPartition); field != "" {
+					add(id, field, reason)
+				} else if p.AcceleratorProfile != nil {
+					if _, err := resolveAcceleratorProfile(c.AcceleratorProfiles, p); err != nil {
+						add(id, "acceleratorProfile", err.Error())
+					}
+				}
+			}
+			if p.PowerCapWatts != 0 && c.MaxPowerCapWatts != 0 && (p.PowerCapWatts < c.MinPowerCapWatts || p.PowerCapWatts > c.MaxPowerCapWatts) {
+				add(id, "powerCapWatts", fmt.Sprintf("power cap %vW is outside the supported range %vW - %vW", p.PowerCapWatts, c.MinPowerCapWatts, c.MaxPowerCapWatts))
+			}
+			for ci, clock := range p.Clocks {
+				limits, ok := c.ClockLimits[clock.Type]
+				if ok && (!clockInRange(clock.MinMHz, limits) || !clockInRange(clock.MaxMHz, limits)) {
+					add(id, fmt.Sprintf("clocks[%d]", ci), fmt.Sprintf("%v clock range %v - %v MHz is outside the supported range %v - %v MHz", clock.Type, clock.MinMHz, clock.MaxMHz, limits.MinMHz, limits.MaxMHz))
+				}
+			}
+			if p.PowerProfile != "" && len(c.PowerProfiles) > 0 && !ValidateList(p.PowerProfile, c.PowerProfiles) {
+				add(id, "powerProfile", fmt.Sprintf("power profile %v is not supported, supported profiles are %v", p.PowerProfile, c.PowerProfiles))
+			}
+			if limits, ok := c.ClockLimits[backend.ClockGFX]; ok && !clockInRange(p.DeterminismClockMHz, limits) {
+				add(id, "determinismClockMHz", fmt.Sprintf("GFX clock %v MHz is outside the supported range %v - %v MHz", p.DeterminismClockMHz, limits.MinMHz, limits.MaxMHz))
+			}
+		}
+		for _, f := range found {
+			issues = append(issues, types.ValidationIssue{
+				Path:     path + "." + f.field,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("GPU IDs %v: %s", f.ids, f.message),
+			})
+		}
+	}
+	return issues
+}
+
+// clockInRange reports whether a clock bound fits the limits, 0 means unset
+func clockInRange(mhz uint32, limits types.ClockRange) bool {
+	return mhz == 0 || mhz >= limits.MinMHz && mhz <= limits.MaxMHz
+}
+
+// CheckCapabilities checks every profile of a config against the partition
+// modes supported by the GPUs, issues are ordered by profile name
+func CheckCapabilities(profiles *partition_pb.GPUConfigProfiles, totalGPUCount int, caps []types.GPUCapabilities) []types.ValidationIssue {
+	issues := []types.ValidationIssue{}
+	for _, name := range ProfileNames(profiles) {
+		issues = append(issues, checkCapabilities(name, profiles.ProfilesList[name], totalGPUCount, caps)...)
+	}
+	return issues
+}
+
+// validateCapabilities checks the selected profile against the GPUs before
+// any partition mode is changed
+func validateCapabilities(name string, profile *partition_pb.GPUConfigProfile, totalGPUCount int, caps []types.GPUCapabilities) error {
+	issues := checkCapabilities(name, profile, totalGPUCount, caps)
+	for _, issue := range issues {
+		log.Printf("%v %v: %v", strings.ToUpper(issue.Severity), issue.Path, issue.Message)
+	}
+	return issuesError(issues)
+}
+
+// commonModes returns the modes supported by all GPUs with known modes
+func commonModes(caps []types.GPUCapabilities, modes func(types.GPUCapabilities) []string) []string {
+	var common []string
+	for _, c := range caps {
+		if len(modes(c)) == 0 {
+			continue
+		}
+		if common == nil {
+			common = modes(c)
+			continue
+		}
+		kept := []string{}
+		for _, m := range common {
+			if ValidateList(m, modes(c)) {
+				kept = append(kept, m)
+			}
+		}
+		common = kept
+	}
+	return common
+}
+
+// publishCapabilities sets the node labels listing the partition modes all
+// GPUs support and the annotation with the modes of each GPU
+func publishCapabilities(caps []types.GPUCapabilities) {
+	capsBytes, err := json.Marshal(caps)
+	if err != nil {
+		log_e.Errorf("failed to marshal partition capabilities %+v err %+v", caps, err)
+		return
+	}
+	capabilitiesMu.Lock()
+	defer capabilitiesMu.Unlock()
+	if string(capsBytes) == lastCapabilities {
+		return
+	}
+
+	if IsStandaloneMode() {
+		updateStandaloneState(func(s *types.NodeState) {
+			s.Capabilities = caps
+		})
+		lastCapabilities = string(capsBytes)
+		return
+	}
+	if nodeName == "" {
+		log.Printf("Not a k8s deployment, skipping partition capability labels")
+		return
+	}
+	compute := commonModes(caps, func(c types.GPUCapabilities) []string { return c.ComputePartitions })
+	memory := commonModes(caps, func(c types.GPUCapabilities) []string { return c.MemoryPartitions })
+	if len(compute) > 0 {
+		if err := kc.AddNodeLabel(nodeName, globals.ComputeCapsLabelKey, strings.Join(compute, "_")); err != nil {
+			log_e.Errorf("Error adding compute partition capability node label: %v", err)
+			return
+		}
+	}
+	if len(memory) > 0 {
+		if err := kc.AddNodeLabel(nodeName, globals.MemoryCapsLabelKey, strings.Join(memory, "_")); err != nil {
+			log_e.Errorf("Error adding memory partition capability node label: %v", err)
+			return
+		}
+	}
+	if err := kc.AddNodeAnnotation(nodeName, globals.CapabilitiesAnnotationKey, string(capsBytes)); err != nil {
+		log_e.Errorf("Error adding partition capability node annotation: %v", err)
+		return
+	}
+	lastCapabilities = string(capsBytes)
+}
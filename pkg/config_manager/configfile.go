@@ -0,0 +1,282 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+)
+
+// Config is a parsed DCM config file
+type Config struct {
+	Profiles *partition_pb.GPUConfigProfiles
+	// GPU client systemd services stopped while partitioning
+	Services []string
+	// fields missing in single profiles, these profiles cannot be applied
+	// while the rest of the config stays usable
+	ProfileErrors map[string][]*ConfigError
+}
+
+// ConfigError locates a problem in the config file
+type ConfigError struct {
+	Line    int
+	Column  int
+	Path    string
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	position := fmt.Sprintf("line %d column %d", e.Line, e.Column)
+	if e.Column == 0 {
+		// yaml syntax errors only carry the line
+		position = fmt.Sprintf("line %d", e.Line)
+	}
+	if e.Path == "" {
+		return fmt.Sprintf("%s: %s", position, e.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", position, e.Path, e.Message)
+}
+
+// configFile is the layout of the config file, it carries both the
+// GPUConfigProfiles and the GPUClientSystemdServices messages
+type configFile struct {
+	Profiles map[string]*partition_pb.GPUConfigProfile `json:"gpu-config-profiles"`
+	Services *partition_pb.GPUServiceList              `json:"gpuClientSystemdServices"`
+}
+
+// matches the slice indexes in the field paths of json errors, e.g. profiles.2
+var sliceIndexPattern = regexp.MustCompile(`\.(\d+)\b`)
+
+// fields every object of the given type must set, a missing number would
+// silently be read as 0
+var requiredConfigFields = map[reflect.Type][]string{
+	reflect.TypeOf((*configFile)(nil)).Elem():                    {profilesPath},
+	reflect.TypeOf((*partition_pb.GPUConfigProfile)(nil)).Elem(): {"profiles"},
+	reflect.TypeOf((*partition_pb.ProfileConfig)(nil)).Elem():    {"computePartition", "memoryPartition", "numGPUsAssigned"},
+	reflect.TypeOf((*partition_pb.GPUServiceList)(nil)).Elem():   {"names"},
+}
+
+// LoadConfig reads and strictly parses a DCM config file, files with a
+// .yaml or .yml extension are parsed as yaml
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAMLConfig(data)
+	default:
+		return parseConfig(data)
+	}
+}
+
+// parseConfig rejects syntax errors, wrongly typed values and unknown or
+// misspelled fields, all reported with their line and column
+func parseConfig(data []byte) (*Config, error) {
+	var file configFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		switch {
+		case errors.As(err, &syntaxErr):
+			// the offset is just past the invalid character
+			return nil, newConfigError(data, syntaxErr.Offset-1, "", syntaxErr.Error())
+		case errors.As(err, &typeErr):
+			path := sliceIndexPattern.ReplaceAllString(typeErr.Field, "[$1]")
+			return nil, newConfigError(data, typeErr.Offset, path,
+				fmt.Sprintf("cannot use %s value as %s", typeErr.Value, typeErr.Type))
+		}
+		return nil, err
+	}
+
+	w := &configWalker{
+		data:          data,
+		dec:           json.NewDecoder(bytes.NewReader(data)),
+		profileErrors: make(map[string][]*ConfigError),
+	}
+	w.dec.UseNumber()
+	if err := w.walk("", "", reflect.TypeOf(file)); err != nil {
+		return nil, err
+	}
+	if _, err := w.dec.Token(); err != io.EOF {
+		return nil, newConfigError(data, w.offset(), "", "unexpected data after the config")
+	}
+
+	return newConfig(&file, w.profileErrors), nil
+}
+
+func newConfig(file *configFile, profileErrors map[string][]*ConfigError) *Config {
+	cfg := &Config{
+		Profiles:      &partition_pb.GPUConfigProfiles{ProfilesList: file.Profiles},
+		Services:      []string{},
+		ProfileErrors: profileErrors,
+	}
+	if file.Services != nil {
+		cfg.Services = file.Services.Names
+	}
+	return cfg
+}
+
+// profileConfigError returns the fields missing in a profile of the config
+func profileConfigError(cfg *Config, name string) error {
+	errs := cfg.ProfileErrors[name]
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, len(errs))
+	for i, cerr := range errs {
+		msgs[i] = cerr.Error()
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+func newConfigError(data []byte, offset int64, path, message string) *ConfigError {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	column := int(offset) - bytes.LastIndexByte(data[:offset], '\n')
+	return &ConfigError{Line: line, Column: column, Path: path, Message: message}
+}
+
+// configWalker walks the tokens of the config next to the Go types they are
+// decoded into, so every field can be checked at its position in the file
+type configWalker struct {
+	data          []byte
+	dec           *json.Decoder
+	profileErrors map[string][]*ConfigError
+}
+
+// offset returns the start of the next token
+func (w *configWalker) offset() int64 {
+	offset := w.dec.InputOffset()
+	for offset < int64(len(w.data)) && strings.IndexByte(" \t\r\n,:", w.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// jsonFieldType returns the type of the struct field decoded from key,
+// keys have to match the json name exactly
+func jsonFieldType(t reflect.Type, key string) (reflect.Type, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == key {
+			return f.Type, true
+		}
+	}
+	return nil, false
+}
+
+func joinConfigPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// walk checks the next value of the config against type t, profile is the
+// name of the profile the value belongs to
+func (w *configWalker) walk(path, profile string, t reflect.Type) error {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	start := w.offset()
+	tok, err := w.dec.Token()
+	if err != nil {
+		return newConfigError(w.data, start, path, err.Error())
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '[':
+		var elem reflect.Type
+		if t != nil && t.Kind() == reflect.Slice {
+			elem = t.Elem()
+		}
+		for i := 0; w.dec.More(); i++ {
+			if err := w.walk(fmt.Sprintf("%s[%d]", path, i), profile, elem); err != nil {
+				return err
+			}
+		}
+	case '{':
+		keys := make(map[string]bool)
+		for w.dec.More() {
+			keyOffset := w.offset()
+			tok, err := w.dec.Token()
+			if err != nil {
+				return newConfigError(w.data, keyOffset, path, err.Error())
+			}
+			key := tok.(string)
+			keyPath := joinConfigPath(path, key)
+			keys[key] = true
+
+			var elem reflect.Type
+			childProfile := profile
+			if t != nil && t.Kind() == reflect.Map {
+				elem = t.Elem()
+				if path == profilesPath {
+					childProfile = key
+				}
+			} else if t != nil && t.Kind() == reflect.Struct {
+				elem, ok = jsonFieldType(t, key)
+				if !ok {
+					return newConfigError(w.data, keyOffset, keyPath, fmt.Sprintf("unknown field %q", key))
+				}
+			}
+			if err := w.walk(keyPath, childProfile, elem); err != nil {
+				return err
+			}
+		}
+		for _, field := range requiredConfigFields[t] {
+			if keys[field] {
+				continue
+			}
+			cerr := newConfigError(w.data, start, joinConfigPath(path, field), "missing required field")
+			if profile == "" {
+				return cerr
+			}
+			w.profileErrors[profile] = append(w.profileErrors[profile], cerr)
+		}
+	}
+	// closing delimiter
+	if _, err := w.dec.Token(); err != nil {
+		return newConfigError(w.data, w.offset(), path, err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// matches the line yaml.v3 reports in its syntax errors
+var yamlErrorLine = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// names of the yaml scalar tags in the terms of the json errors
+var yamlTagNames = map[string]string{
+	"!!str":   "string",
+	"!!int":   "number",
+	"!!float": "number",
+	"!!bool":  "bool",
+}
+
+// parseYAMLConfig parses a yaml config with the schema and checks of the
+// json config, the positions reported point into the yaml file
+func parseYAMLConfig(data []byte) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &ConfigError{Line: line, Message: m[2]}
+		}
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		return nil, &ConfigError{Line: 1, Column: 1, Message: "empty config"}
+	}
+
+	w := &yamlConfigWalker{profileErrors: make(map[string][]*ConfigError)}
+	value, err := w.walk(doc.Content[0], "", "", reflect.TypeOf(configFile{}))
+	if err != nil {
+		return nil, err
+	}
+
+	// the yaml has been checked against the schema, decode it through json
+	// so both formats map onto the same GPUConfigProfiles fields
+	jsonData, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var file configFile
+	if err := json.Unmarshal(jsonData, &file); err != nil {
+		return nil, err
+	}
+	return newConfig(&file, w.profileErrors), nil
+}
+
+type yamlConfigWalker struct {
+	profileErrors map[string][]*ConfigError
+}
+
+func yamlNodeError(node *yaml.Node, path, message string) *ConfigError {
+	return &ConfigError{Line: node.Line, Column: node.Column, Path: path, Message: message}
+}
+
+func yamlKindName(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "object"
+	case yaml.SequenceNode:
+		return "array"
+	}
+	if name, ok := yamlTagNames[node.ShortTag()]; ok {
+		return name
+	}
+	return node.ShortTag()
+}
+
+// walk checks a yaml node against type t and returns its value in the
+// form encoding/json marshals, profile is the profile the node belongs to
+func (w *yamlConfigWalker) walk(node *yaml.Node, path, profile string, t reflect.Type) (interface{}, error) {
+	for node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if node.Kind == yaml.ScalarNode && node.ShortTag() == "!!null" {
+		return nil, nil
+	}
+	typeError := func() error {
+		return yamlNodeError(node, path, fmt.Sprintf("cannot use %s value as %s", yamlKindName(node), t))
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		if t == nil || t.Kind() != reflect.Slice {
+			return nil, typeError()
+		}
+		values := make([]interface{}, len(node.Content))
+		for i, item := range node.Content {
+			v, err := w.walk(item, fmt.Sprintf("%s[%d]", path, i), profile, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			values[i] = v
+		}
+		return values, nil
+
+	case yaml.MappingNode:
+		if t == nil || (t.Kind() != reflect.Map && t.Kind() != reflect.Struct) {
+			return nil, typeError()
+		}
+		values := make(map[string]interface{})
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			keyNode, valueNode := node.Content[i], node.Content[i+1]
+			key := keyNode.Value
+			keyPath := joinConfigPath(path, key)
+
+			elem := t
+			childProfile := profile
+			if t.Kind() == reflect.Map {
+				elem = t.Elem()
+				if path == profilesPath {
+					childProfile = key
+				}
+			} else {
+				var ok bool
+				elem, ok = jsonFieldType(t, key)
+				if !ok {
+					return nil, yamlNodeError(keyNode, keyPath, fmt.Sprintf("unknown field %q", key))
+				}
+			}
+			if _, ok := values[key]; ok {
+				return nil, yamlNodeError(keyNode, keyPath, fmt.Sprintf("duplicate field %q", key))
+			}
+			v, err := w.walk(valueNode, keyPath, childProfile, elem)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = v
+		}
+		for _, field := range requiredConfigFields[t] {
+			if _, ok := values[field]; ok {
+				continue
+			}
+			cerr := yamlNodeError(node, joinConfigPath(path, field), "missing required field")
+			if profile == "" {
+				return nil, cerr
+			}
+			w.profileErrors[profile] = append(w.profileErrors[profile], cerr)
+		}
+		return values, nil
+	}
+
+	// scalars
+	if t == nil {
+		return nil, typeError()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		if node.ShortTag() != "!!str" {
+			return nil, typeError()
+		}
+		return node.Value, nil
+	case reflect.Uint32:
+		if node.ShortTag() != "!!int" {
+			return nil, typeError()
+		}
+		var n uint32
+		if err := node.Decode(&n); err != nil {
+			return nil, yamlNodeError(node, path, fmt.Sprintf("cannot use %v as %s", node.Value, t))
+		}
+		return n, nil
+	case reflect.Bool:
+		if node.ShortTag() != "!!bool" {
+			return nil, typeError()
+		}
+		var b bool
+		if err := node.Decode(&b); err != nil {
+			return nil, yamlNodeError(node, path, fmt.Sprintf("cannot use %v as %s", node.Value, t))
+		}
+		return b, nil
+	}
+	return nil, typeError()
+}
@@ -16,27 +16,20 @@ limitations under the License.
 
 package configmanager
 
-/*
-#cgo CFLAGS: -I/device-config-manager/build/assets/amd_smi
-#cgo LDFLAGS: -L/device-config-manager/build/assets -lamd_smi -ldrm_amdgpu -ldrm
-#include "/device-config-manager/build/assets/amdsmi.h"
-*/
-import "C"
 import (
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"reflect"
 	"sync"
 	"time"
-	"unsafe"
 
 	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
 	"github.com/ROCm/device-config-manager/pkg/amdgpu/k8sclient"
 	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
 	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
@@ -52,9 +45,16 @@ var kc *k8sclient.K8sClient = k8sclient.NewClient(context.Background())
 var nodeName string = k8sclient.GetNodeName()
 var kmmDriverEnabled = k8sclient.IsKMMDriverEnabled()
 
-var sockets []C.amdsmi_socket_handle
+var gpuBackend backend.GPUBackend
+
+// backendMu is held from initGPUBackend until shutDownAMDSMI, so a drift
+// check or gRPC query cannot shut the backend down under a partition run
+var backendMu sync.Mutex
 var totalGPUCount int
 var partition_failed bool = false
+
+// set when a partition call of the last attempt failed with busy
+var gpu_busy bool = false
 var partStatus types.PartitionStatus
 
 var (
@@ -67,26 +67,121 @@ var (
 const logDivider = "#####################################"
 const gpuidDivider = "***************************************************************************************************"
 
+// SetGPUBackend overrides the device access backend, by default it is
+// selected through the DCM_GPU_BACKEND environment variable
+func SetGPUBackend(b backend.GPUBackend) {
+	gpuBackend = b
+}
+
+func getGPUBackend() (backend.GPUBackend, error) {
+	if gpuBackend == nil {
+		b, err := backend.New(backend.NameFromEnv())
+		if err != nil {
+			return nil, err
+		}
+		gpuBackend = b
+	}
+	return gpuBackend, nil
+}
+
+// initGPUBackend initializes the selected backend, when amdsmi cannot be
+// initialized the partition attributes in sysfs are used instead. On success
+// backendMu is held until shutDownAMDSMI
+func initGPUBackend() (backend.GPUBackend, error) {
+	backendMu.Lock()
+	gpu, err := tryInitGPUBackend()
+	if err != nil {
+		backendMu.Unlock()
+	}
+	return gpu, err
+}
+
+// backendBusy reports whether the GPU backend is in use, e.g. by a partition run
+func backendBusy() bool {
+	if !backendMu.TryLock() {
+		return true
+	}
+	backendMu.Unlock()
+	return false
+}
+
+func tryInitGPUBackend() (backend.GPUBackend, error) {
+	gpu, err := getGPUBackend()
+	if err == nil {
+		if err = gpu.Init(); err == nil {
+			return gpu, nil
+		}
+	}
+	if backend.NameFromEnv() != backend.BackendAMDSMI || !backend.SysfsFallbackEnabled() {
+		return nil, err
+	}
+
+	log_e.Errorf("Failed to initialize AMD SMI: %v, falling back to sysfs partition interface", err)
+	sysfs := backend.NewSysfsBackend(backend.SysfsRootFromEnv())
+	if sysfsErr := sysfs.Init(); sysfsErr != nil {
+		log_e.Errorf("sysfs partition interface not usable: %v", sysfsErr)
+		return nil, err
+	}
+	log.Printf("Using sysfs partition interface under %v", backend.SysfsRootFromEnv())
+	return sysfs, nil
+}
+
+func setProfileStateLabel(state string) {
+	recordProfileState(state)
+	if IsStandaloneMode() {
+		updateStandaloneState(func(s *types.NodeState) {
+			s.ProfileState = state
+		})
+		return
+	}
+	if nodeName == "" {
+		log.Printf("Not a k8s deployment, skipping %v=%v node label", globals.ProfileStateLabelKey, state)
+		return
+	}
+	err := kc.AddNodeLabel(nodeName, globals.ProfileStateLabelKey, state)
+	if err != nil {
+		log.Printf("Error adding status node label: %s\n", err.Error())
+	}
+}
+
 func generateK8sEvent(err error, event_n string, partStatus types.PartitionStatus) {
+	if err != nil {
+		recordFailureEvent(event_n)
+	}
+	publishNodeEvent(event_n, partStatus)
+	if IsStandaloneMode() {
+		log.Printf("Event %v: %v", event_n, partStatus.Reason)
+		updateStandaloneState(func(s *types.NodeState) {
+			s.LastEvent = event_n
+			s.PartitionStatus = partStatus
+		})
+		return
+	}
+	if nodeName == "" {
+		log.Printf("Not a k8s deployment, skipping event %v", event_n)
+		return
+	}
+	msgbytes, merr := json.Marshal(partStatus)
+	if merr != nil {
+		log_e.Errorf("failed to marshal partition status message %+v err %+v", partStatus, merr)
+		return
+	}
+	createK8sEvent(err, event_n, string(msgbytes))
+}
+
+// createK8sEvent raises an event on the DCM pod, a warning when err is set
+func createK8sEvent(err error, event_n string, message string) {
 	k8sPodNamespace := k8sclient.GetPodNameSpace()
 	k8sPodName := k8sclient.GetPodName()
 	currTime := time.Now().UTC()
 
 	eventType := v1.EventTypeNormal
 	reason := event_n
-	var message string
 
 	if err != nil {
 		eventType = v1.EventTypeWarning
 	}
 
-	msgbytes, err := json.Marshal(partStatus)
-	if err != nil {
-		log_e.Errorf("failed to marshal partition status message %+v err %+v", partStatus, err)
-		return
-	}
-	message = string(msgbytes)
-
 	evtObj := createEventObject(event_n, k8sPodNamespace, k8sPodName, currTime, eventType, reason, message)
 	kc.CreateEvent(evtObj)
 }
@@ -97,6 +192,9 @@ func GetPartitionProfile() (string, error) {
 	log.Printf("Partition profile info:\n")
 	defer log.Println(logDivider)
 	var selectedProfile string
+	if IsStandaloneMode() {
+		return getStandaloneProfile()
+	}
 	if nodeName == "" {
 		err := errors.New("not a k8s deployment")
 		return "", err
@@ -123,6 +221,48 @@ func GetPartitionProfile() (string, error) {
 	return selectedProfile, nil
 }
 
+// selectPartitionProfile persists the profile as the selected one, in the
+// node label or the standalone profile file, and reports whether the
+// selection changed
+func selectPartitionProfile(profile string) (bool, error) {
+	if IsStandaloneMode() {
+		return selectStandaloneProfile(profile)
+	}
+	if nodeName == "" {
+		return false, errors.New("not a k8s deployment")
+	}
+	labels, err := kc.GetNodeLabel(nodeName)
+	if err != nil {
+		return false, err
+	}
+	if labels[globals.LabelKey] == profile {
+		return false, nil
+	}
+	return true, kc.AddNodeLabel(nodeName, globals.LabelKey, profile)
+}
+
+// appliedSelection is a selection change made by the gRPC Apply, which
+// triggers its run itself, the watcher skips the change once
+var appliedSelection string
+
+func setAppliedSelection(profile string) {
+	mu.Lock()
+	defer mu.Unlock()
+	appliedSelection = profile
+}
+
+// takeAppliedSelection reports whether the selection of the profile was made
+// by the gRPC Apply
+func takeAppliedSelection(profile string) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	if appliedSelection == "" || appliedSelection != profile {
+		return false
+	}
+	appliedSelection = ""
+	return true
+}
+
 func getAMDSMIStatusString(code int) string {
 	if status, exists := globals.AmdsmiStatusStrings[code]; exists {
 		return status
@@ -131,27 +271,32 @@ func getAMDSMIStatusString(code int) string {
 }
 
 func StartFileWatcher(selectedProfile string) {
-	log.Printf("Adding file watcher for %v", globals.JsonFilePath)
+	configPath := ConfigFilePath()
+	log.Printf("Adding file watcher for %v", configPath)
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		log.Print(err)
+		setComponentHealth(componentConfigWatcher, err)
 		return
 	}
 	defer watcher.Close()
 
-	if _, err := os.Stat(globals.JsonFilePath); os.IsNotExist(err) {
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		<-make(chan struct{})
 	}
 	// Add the JSON file to the watcher
-	err = watcher.Add(globals.JsonFilePath)
+	err = watcher.Add(configPath)
 	if err != nil {
 		log.Print(err)
+		setComponentHealth(componentConfigWatcher, err)
 		return
 	}
 
-	log.Printf("starting file watcher for %v", globals.JsonFilePath)
+	log.Printf("starting file watcher for %v", configPath)
+	setComponentHealth(componentConfigWatcher, nil)
 	// Watch for changes
 	go func() {
+		defer setComponentHealth(componentConfigWatcher, errWatcherStopped)
 		for {
 			select {
 			case event, ok := <-watcher.Events:
@@ -167,12 +312,14 @@ func StartFileWatcher(selectedProfile string) {
 					}
 					if selectedProfile != "" {
 						TriggerRetryLoop(selectedProfile, "configmap watcher")
+					} else {
+						ValidateConfigFile()
 					}
 				} else {
 					log.Printf("Event %v", event)
 				}
-				watcher.Remove(globals.JsonFilePath)
-				watcher.Add(globals.JsonFilePath)
+				watcher.Remove(configPath)
+				watcher.Add(configPath)
 			case err, ok := <-watcher.Errors:
 				if !ok {
 					log.Print("Event channel closed, error")
@@ -187,90 +334,6 @@ func StartFileWatcher(selectedProfile string) {
 	<-make(chan struct{})
 }
 
-func convertComputePartitonType(partitionType string) C.amdsmi_compute_partition_type_t {
-	switch partitionType {
-	case "CPX":
-		return C.AMDSMI_COMPUTE_PARTITION_CPX
-	case "SPX":
-		return C.AMDSMI_COMPUTE_PARTITION_SPX
-	case "DPX":
-		return C.AMDSMI_COMPUTE_PARTITION_DPX
-	case "QPX":
-		return C.AMDSMI_COMPUTE_PARTITION_QPX
-	default:
-		log_e.Errorf("Unknown compute partition type: %s, using default type SPX", partitionType)
-		return C.AMDSMI_COMPUTE_PARTITION_SPX // default value
-	}
-}
-
-func convertMemoryPartitionType(memoryPartition string) C.amdsmi_memory_partition_type_t {
-	switch memoryPartition {
-	case "NPS1":
-		return C.AMDSMI_MEMORY_PARTITION_NPS1
-	case "NPS2":
-		return C.AMDSMI_MEMORY_PARTITION_NPS2
-	case "NPS4":
-		return C.AMDSMI_MEMORY_PARTITION_NPS4
-	default:
-		log_e.Errorf("Unknown memory partition type: %s, using default type NPS1", memoryPartition)
-		return C.AMDSMI_MEMORY_PARTITION_NPS1 // default value
-	}
-}
-
-func amdsmiGetSocketHandles() ([]C.amdsmi_socket_handle, int) {
-	var socketCount C.uint32_t
-	ret := C.amdsmi_get_socket_handles(&socketCount, nil)
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get socket count")
-		return nil, 0
-	}
-
-	// allocating the memory for the sockets
-	sockets := make([]C.amdsmi_socket_handle, socketCount)
-
-	// get the actual socket handles
-	ret = C.amdsmi_get_socket_handles(&socketCount, &sockets[0])
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get socket handles")
-		return nil, 0
-	}
-
-	// return the socket handles and the count
-	return sockets, int(socketCount)
-}
-
-func amdsmiGetProcessorHandles(socket C.amdsmi_socket_handle) ([]C.amdsmi_processor_handle, int) {
-	var device_count C.uint32_t
-	ret := C.amdsmi_get_processor_handles(socket, &device_count, nil)
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get device count")
-		return nil, 0
-	}
-
-	// allocating the memory for the processor
-	processors := make([]C.amdsmi_processor_handle, device_count)
-
-	ret = C.amdsmi_get_processor_handles(socket, &device_count, &processors[0])
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get processor handles")
-		return nil, 0
-	}
-
-	// return the socket handles and the device count
-	return processors, int(device_count)
-}
-
-func amdsmiGetProcessorType(processor_handle C.amdsmi_processor_handle) (C.processor_type_t, error) {
-	var processor_type C.processor_type_t
-	ret := C.amdsmi_get_processor_type(processor_handle, &processor_type)
-	if ret != 0 {
-		log.Printf("Error: %d\n", ret)
-		err := errors.New("AMD SMI get processor type failed")
-		return processor_type, err
-	}
-	return processor_type, nil
-}
-
 func createGPUIDList(filter_ids []uint32, totalGPUCount int) []int {
 	result := []int{}
 outer:
@@ -285,79 +348,22 @@ outer:
 	return result
 }
 
-func validateProfile(profile *partition_pb.GPUConfigProfile, totalGPUCount int) error {
-	devices_conf_count := len(profile.Profiles)
-	profiles := profile.Profiles
-	total_devices := 0
-	devicefilter := profile.Filters
-	if len(devicefilter.Id) > totalGPUCount {
-		log.Printf("Device filter count %d exceeding existing GPU count %d in node", len(devicefilter.Id), totalGPUCount)
-		err := errors.New("GPU ID list specified in the device filter is invalid, list length is exceeding the total number of GPUs available on this node")
-		log.Printf("ERROR %v", err)
-		return err
-	}
-
-	for _, id := range devicefilter.Id {
-		if int(id)+1 > totalGPUCount {
-			log.Printf("Invalid GPU ID specified in skippedGPUs list: %v Valid GPU indices : 0 - %v", id, totalGPUCount-1)
-			err := errors.New("invalid gpu id")
-			return err
-		}
-	}
-
-	for i := range devices_conf_count {
-		nod := profiles[i].NumGPUsAssigned
-		total_devices = total_devices + int(nod)
-	}
-	if total_devices+len(devicefilter.Id) != totalGPUCount {
-		err := errors.New("the total of all numGPUsAssigned values across profiles, combined with the count of IDs in the skippedGPUs list, does not equal the total number of GPUs available on this node")
-		log.Printf("ERROR %v", err)
-		return err
-	}
-	gpu_ids_list := createGPUIDList(devicefilter.Id, totalGPUCount)
-	log.Printf("Usable GPU IDs for partitioning %v", gpu_ids_list)
-	currentMemory := profiles[0].MemoryPartition
-	for i := 0; i < devices_conf_count; i++ {
-		currentCompute := profiles[i].ComputePartition
-		err := checkInvalidPartitionType(currentCompute, profiles[i].MemoryPartition)
-		if err != nil {
-			log.Printf("Invalid partition types %v-%v", currentCompute, currentMemory)
-			return err
-		}
-		if currentMemory != profiles[i].MemoryPartition {
-			log.Printf("All profiles must have a common memory type NPS1, NPS2 or NPS4")
-			err := errors.New("profile cannot have combination of NPS1, NPS2 and NPS4 memory types")
-			return err
-		}
-		nod := profiles[i].NumGPUsAssigned
-		log.Printf("Partitioning %v devices with compute partition type %v and memory type %v", nod, currentCompute, currentMemory)
-	}
-	log.Println("Profile validation successful")
-	return nil
-}
-
-func getCurrentGPUComputePartition(processor_handle C.amdsmi_processor_handle) string {
-	var len C.uint32_t = 4
-	computePartition := make([]C.char, len)
-	ret := C.amdsmi_get_gpu_compute_partition(processor_handle, &computePartition[0], len)
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get compute partition %v", ret)
+func getCurrentGPUComputePartition(gpu backend.GPUBackend, gpuID int) string {
+	computePartition, err := gpu.GetComputePartition(gpuID)
+	if err != nil {
+		log_e.Errorf("Failed to get compute partition %v", backend.StatusCode(err))
 		return ""
 	}
-	cStr := (*C.char)(unsafe.Pointer(&computePartition[0]))
-	return C.GoString(cStr)
+	return computePartition
 }
 
-func getCurrentGPUMemoryPartition(processor_handle C.amdsmi_processor_handle) string {
-	var len C.uint32_t = 5
-	memoryPartition := make([]C.char, len)
-	ret := C.amdsmi_get_gpu_memory_partition(processor_handle, &memoryPartition[0], len)
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to get memory partition %v", ret)
+func getCurrentGPUMemoryPartition(gpu backend.GPUBackend, gpuID int) string {
+	memoryPartition, err := gpu.GetMemoryPartition(gpuID)
+	if err != nil {
+		log_e.Errorf("Failed to get memory partition %v", backend.StatusCode(err))
 		return ""
 	}
-	cStr := (*C.char)(unsafe.Pointer(&memoryPartition[0]))
-	return C.GoString(cStr)
+	return memoryPartition
 }
 
 func populateGPUEventStatus(gpu_id int, partitionType string, status string, message string, idx int) {
@@ -366,11 +372,13 @@ func populateGPUEventStatus(gpu_id int, partitionType string, status string, mes
 	partStatus.GPUStatus[idx].PartitionType = partitionType
 	partStatus.GPUStatus[idx].Status = status
 	partStatus.GPUStatus[idx].Message = message
+	publishGPUStatus(partStatus, partStatus.GPUStatus[idx])
+	journalGPUStatus(partStatus.GPUStatus)
 }
 
 // retryMemoryPartitionWithWait attempts to recover the memory partition by reloading KMM driver,
 // wait for the memory partition to match the expected value, and updates partition_failed accordingly.
-func retryMemoryPartitionWithWait(processor_handle C.amdsmi_processor_handle, expectMemoryPartition string, nodeName string, kc *k8sclient.K8sClient) bool {
+func retryMemoryPartitionWithWait(gpu backend.GPUBackend, gpuID int, expectMemoryPartition string, nodeName string, kc *k8sclient.K8sClient) bool {
 	log.Println("Attempting memoryPartitionHandling as recovery step...")
 	if !memoryPartitionHandling() {
 		log.Println("Memory partition handling failed, cannot recover memory partition.")
@@ -389,7 +397,7 @@ func retryMemoryPartitionWithWait(processor_handle C.amdsmi_processor_handle, ex
 			success = false
 			break
 		case <-ticker.C:
-			if getCurrentGPUMemoryPartition(processor_handle) == expectMemoryPartition {
+			if getCurrentGPUMemoryPartition(gpu, gpuID) == expectMemoryPartition {
 				log.Println("Memory partition now matches expected value.")
 				success = true
 				break
@@ -409,180 +417,218 @@ func retryMemoryPartitionWithWait(processor_handle C.amdsmi_processor_handle, ex
 	}
 }
 
-func amdSMIHelper(selectedProfile string, profile *partition_pb.GPUConfigProfile) {
+func amdSMIHelper(ctx context.Context, gpu backend.GPUBackend, selectedProfile string, profile *partition_pb.GPUConfigProfile) {
 
 	log.Print("AMD SMI Initialized successfully.")
-	sockets, totalGPUCount = amdsmiGetSocketHandles()
-	podList := kc.GetPods(nodeName)
+	totalGPUCount, _ = gpu.GetGPUCount()
 	if totalGPUCount == 0 {
 		partStatus.Reason = "Partition failed with reason: 0 sockets found"
 		generateK8sEvent(errors.New("no sockets found"), globals.K8EventAMDSMIAPIFailure, partStatus)
-		err := kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
 	}
-	var processor_handles []C.amdsmi_processor_handle
-	var device_count int
 	var gpu_id int
 	var partition_err_reason string
 
-	if profile.Filters == nil {
-		profile.Filters = &partition_pb.SkippedGPUs{}
-		profile.Filters.Id = []uint32{}
-	}
+	normalizeProfile(profile)
 
 	log.Print("Total number of GPUs in the node ", totalGPUCount)
 	log.Printf("Skipped GPU IDs for partitioning %v", profile.Filters.Id)
-	profiles := profile.Profiles
-	idx := 0
-
-	log.Println("\n------------------------------------\n")
+	log.Print("\n------------------------------------\n")
 	log.Println("\nValidating the selected profile.")
 	log.Printf("Profile name: %+v\n", selectedProfile)
 	log.Printf("Profile info: %+v\n", profile)
-	err := validateProfile(profile, totalGPUCount)
+	err := validateProfile(selectedProfile, profile, totalGPUCount)
+	if err == nil {
+		// reject modes the GPU model does not support before changing any GPU
+		err = validateCapabilities(selectedProfile, profile, totalGPUCount, discoverCapabilities(gpu, totalGPUCount))
+	}
 	if err != nil {
 		log.Println("Profile validation failed. Could not partition.")
 	}
-	log.Println("\n------------------------------------\n")
+	log.Print("\n------------------------------------\n")
 	if err != nil {
 		partStatus.Reason = fmt.Sprintf("Partition failed with reason: %v", err)
-		generateK8sEvent(err, globals.K8EventInvalidProfile, partStatus)
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		generateK8sEvent(err, validationEvent(err), partStatus)
+		setProfileStateLabel("failure")
+		return
+	}
+	plan, err := buildPartitionPlan(gpu, selectedProfile, profile, totalGPUCount)
+	if err != nil {
+		log_e.Errorf("Error %v", err)
+		partStatus.Reason = fmt.Sprintf("AMD-SMI API error : %v", err)
+		generateK8sEvent(err, globals.K8EventAMDSMIAPIFailure, partStatus)
+		setProfileStateLabel("failure")
 		return
 	}
-	gpu_ids_list := createGPUIDList(profile.Filters.Id, totalGPUCount)
 	// Allocating memory based on gpuCount
-	partStatus.GPUStatus = make([]types.GPUPartitionStatus, len(gpu_ids_list))
+	partStatus.GPUStatus = make([]types.GPUPartitionStatus, len(plan.GPUs))
 	partition_needed := false
 	partition_failed = false
-	for i := 0; i < len(profile.Profiles); i++ {
-		currentCompute := profiles[i].ComputePartition
-		currentMemory := profiles[i].MemoryPartition
-		partitionType := currentCompute + "-" + currentMemory
-		nod := profiles[i].NumGPUsAssigned
-		for j := 0; j < int(nod); j++ {
-			log.Printf("\n%v\n\n", gpuidDivider)
-			gpu_id = gpu_ids_list[idx]
-			log.Printf("GPU ID %v\n", gpu_id)
-			log.Printf("Requested compute partition %v", currentCompute)
-			log.Printf("Requested memory partition %v", currentMemory)
-			processor_handles, device_count = amdsmiGetProcessorHandles(sockets[gpu_id])
-			log.Printf("Existing Device count : %d", device_count)
-			processor_handle := processor_handles[0]
-			processor_type, err := amdsmiGetProcessorType(processor_handle)
-			if err != nil {
-				log_e.Errorf("Error %v", err)
-				partStatus.Reason = fmt.Sprintf("AMD-SMI API error : %v", err)
-				generateK8sEvent(err, globals.K8EventAMDSMIAPIFailure, partStatus)
-				err := kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-				if err != nil {
-					log.Printf("Error adding status node label: %s\n", err.Error())
-				}
-				return
-			}
-			if processor_type != C.AMDSMI_PROCESSOR_TYPE_AMD_GPU {
-				log.Print("Expected AMDSMI_PROCESSOR_TYPE_AMD_GPU device type!\n")
-				continue
-			}
-
-			existingCompute := getCurrentGPUComputePartition(processor_handle)
-			existingMemory := getCurrentGPUMemoryPartition(processor_handle)
-
-			if (currentCompute == existingCompute) && (currentMemory == existingMemory) {
-				log.Println("Existing compute and memory partition is same as the requested partition! Skipping partitioning for this GPU !")
-				populateGPUEventStatus(gpu_id, partitionType, "Success", "Partition not required", idx)
-				idx = idx + 1
-				log.Printf("\n%v\n", gpuidDivider)
-				continue
+	gpu_busy = false
+	for idx, gpuPlan := range plan.GPUs {
+		currentCompute := gpuPlan.TargetCompute
+		currentMemory := gpuPlan.TargetMemory
+		partitionType := gpuPlan.PartitionType
+		log.Printf("\n%v\n\n", gpuidDivider)
+		gpu_id = gpuPlan.GpuID
+		log.Printf("GPU ID %v\n", gpu_id)
+		log.Printf("Requested compute partition %v", currentCompute)
+		log.Printf("Requested memory partition %v", currentMemory)
+		device_count, _ := gpu.GetProcessorCount(gpu_id)
+		log.Printf("Existing Device count : %d", device_count)
+
+		existingCompute := gpuPlan.CurrentCompute
+		existingMemory := gpuPlan.CurrentMemory
+
+		if !gpuPlan.ComputeChange && !gpuPlan.MemoryChange {
+			log.Println("Existing compute and memory partition is same as the requested partition! Skipping partitioning for this GPU !")
+			populateGPUEventStatus(gpu_id, partitionType, "Success", "Partition not required", idx)
+			if gpuPlan.TargetAcceleratorProfile != nil {
+				partStatus.GPUStatus[idx].AcceleratorProfile = acceleratorProfileStatus(gpu, gpu_id)
 			}
+			log.Printf("\n%v\n", gpuidDivider)
+			continue
+		}
 
-			log.Println("Memory partition :")
-			partition_needed = true
-			if currentMemory != existingMemory {
-				log.Println("Triggering memory partition !!")
-				log.Printf("Existing memory partition: %s\n", existingMemory)
-
-				memoryType := convertMemoryPartitionType(currentMemory)
-				ret_n := C.amdsmi_set_gpu_memory_partition(processor_handle, memoryType)
-				updatedMemory := getCurrentGPUMemoryPartition(processor_handle)
-				if ret_n != C.AMDSMI_STATUS_SUCCESS || (updatedMemory == existingMemory) {
-					partition_err_reason = getAMDSMIStatusString(int(ret_n))
-					log_e.Errorf("Failed to memory partition %v \n", partition_err_reason)
-					if ret_n == C.AMDSMI_STATUS_BUSY {
-						log_e.Errorf("There might be existing pods/daemonsets on the cluster keeping the GPU resource busy, please remove them and retry. Pods list on this node: %v", podList)
-					}
-					// when KMM driver is being used
-					// try to recover the memory partition by reloading KMM driver
-					partition_failed = true
-					if nodeName != "" && kmmDriverEnabled {
-						partition_failed = retryMemoryPartitionWithWait(processor_handle, currentMemory, nodeName, kc)
-					}
-					if partition_failed {
-						err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-						if err != nil {
-							log.Printf("Error adding status node label: %s\n", err.Error())
-						}
-					}
-				} else {
-					log.Println("Memory partition successful !!")
-					log.Printf("Updated Memory Type %v\n", updatedMemory)
+		log.Println("Memory partition :")
+		partition_needed = true
+		if gpuPlan.MemoryChange {
+			log.Println("Triggering memory partition !!")
+			log.Printf("Existing memory partition: %s\n", existingMemory)
+
+			start := time.Now()
+			err_n := gpu.SetMemoryPartition(gpu_id, currentMemory)
+			updatedMemory := getCurrentGPUMemoryPartition(gpu, gpu_id)
+			observePhase(phaseMemoryPartition, start)
+			if err_n != nil || (updatedMemory == existingMemory) {
+				partition_err_reason = getAMDSMIStatusString(backend.StatusCode(err_n))
+				log_e.Errorf("Failed to memory partition %v \n", partition_err_reason)
+				if backend.IsBusy(err_n) {
+					reportBusyGPU(gpu)
+				}
+				// when KMM driver is being used
+				// try to recover the memory partition by reloading KMM driver
+				partition_failed = true
+				if nodeName != "" && kmmDriverEnabled {
+					start := time.Now()
+					partition_failed = retryMemoryPartitionWithWait(gpu, gpu_id, currentMemory, nodeName, kc)
+					observePhase(phaseKMMRecoveryWait, start)
+				}
+				if partition_failed {
+					setProfileStateLabel("failure")
 				}
 			} else {
-				log.Println("Existing and requested memory partition matching! Memory partition not required !!")
+				log.Println("Memory partition successful !!")
+				log.Printf("Updated Memory Type %v\n", updatedMemory)
 			}
+		} else {
+			log.Println("Existing and requested memory partition matching! Memory partition not required !!")
+		}
 
-			log.Println("Compute partition :")
-			existingCompute = getCurrentGPUComputePartition(processor_handle)
-
-			if currentCompute != existingCompute {
-				log.Println("Triggering compute partition !!")
-				log.Printf("Existing compute partition: %s\n", existingCompute)
-				computeType := convertComputePartitonType(currentCompute)
-
-				ret_n := C.amdsmi_set_gpu_compute_partition(processor_handle, computeType)
-				// check for change in profile name or config map change
-
-				updatedCompute := getCurrentGPUComputePartition(processor_handle)
-				if ret_n != C.AMDSMI_STATUS_SUCCESS {
-					partition_err_reason = getAMDSMIStatusString(int(ret_n))
-					log_e.Errorf("Failed to compute partition %v \n", partition_err_reason)
-					if ret_n == C.AMDSMI_STATUS_BUSY {
-						log_e.Errorf("There might be existing pods/daemonsets on the cluster keeping the GPU resource busy, please remove them and retry. Pods list on this node: %v", podList)
-					}
-					err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-					if err != nil {
-						log.Printf("Error adding status node label: %s\n", err.Error())
-					}
-					partition_failed = true
-				} else {
-					log.Println("Compute partition successful !!")
-					log.Printf("Updated Compute Type %v", updatedCompute)
+		log.Println("Compute partition :")
+		// a memory partition change can reset the compute partition
+		existingCompute = getCurrentGPUComputePartition(gpu, gpu_id)
+
+		start := time.Now()
+		if gpuPlan.TargetAcceleratorProfile != nil {
+			if err_n := applyAcceleratorProfile(gpu, gpu_id, *gpuPlan.TargetAcceleratorProfile); err_n != nil {
+				partition_err_reason = getAMDSMIStatusString(backend.StatusCode(err_n))
+				log_e.Errorf("Failed to set the accelerator profile %v \n", partition_err_reason)
+				if backend.IsBusy(err_n) {
+					reportBusyGPU(gpu)
 				}
-			} else {
-				log.Println("Existing and requested compute partition matching! Compute partition not required !!")
+				setProfileStateLabel("failure")
+				partition_failed = true
 			}
-			if partition_failed {
-				populateGPUEventStatus(gpu_id, partitionType, "Failure", fmt.Sprintf("Partition failed with reason: %v", partition_err_reason), idx)
-				partStatus.Reason = fmt.Sprintf("Partition failed with reason: %v", partition_err_reason)
+		} else if currentCompute != existingCompute {
+			log.Println("Triggering compute partition !!")
+			log.Printf("Existing compute partition: %s\n", existingCompute)
+
+			err_n := gpu.SetComputePartition(gpu_id, currentCompute)
+			// check for change in profile name or config map change
+
+			updatedCompute := getCurrentGPUComputePartition(gpu, gpu_id)
+			if err_n != nil {
+				partition_err_reason = getAMDSMIStatusString(backend.StatusCode(err_n))
+				log_e.Errorf("Failed to compute partition %v \n", partition_err_reason)
+				if backend.IsBusy(err_n) {
+					reportBusyGPU(gpu)
+				}
+				setProfileStateLabel("failure")
+				partition_failed = true
 			} else {
-				populateGPUEventStatus(gpu_id, partitionType, "Success", "Successfully partitioned", idx)
+				log.Println("Compute partition successful !!")
+				log.Printf("Updated Compute Type %v", updatedCompute)
 			}
-			idx = idx + 1
-			log.Printf("\n%v\n", gpuidDivider)
+		} else {
+			log.Println("Existing and requested compute partition matching! Compute partition not required !!")
 		}
-		if partition_needed && !partition_failed {
-			log.Printf("Successfully Partitioned GPUs of profile %d", i+1)
+		observePhase(phaseComputePartition, start)
+		if partition_failed {
+			populateGPUEventStatus(gpu_id, partitionType, "Failure", fmt.Sprintf("Partition failed with reason: %v", partition_err_reason), idx)
+			partStatus.Reason = fmt.Sprintf("Partition failed with reason: %v", partition_err_reason)
+		} else {
+			populateGPUEventStatus(gpu_id, partitionType, "Success", "Successfully partitioned", idx)
+			if gpuPlan.TargetAcceleratorProfile != nil {
+				partStatus.GPUStatus[idx].AcceleratorProfile = acceleratorProfileStatus(gpu, gpu_id)
+			}
 		}
+		log.Printf("\n%v\n", gpuidDivider)
 	}
 
+	// GPU settings are applied once the partitions are final
+	for idx, gpuPlan := range plan.GPUs {
+		if partStatus.GPUStatus[idx].Status != "Success" {
+			continue
+		}
+		if gpuPlan.SettingsChange() {
+			partition_needed = true
+		}
+		if err := applyGPUSettings(gpu, gpuPlan, &partStatus.GPUStatus[idx]); err != nil {
+			partition_err_reason = getAMDSMIStatusString(backend.StatusCode(err))
+			log_e.Errorf("GPU ID %v: failed to apply the GPU settings: %v", gpuPlan.GpuID, err)
+			populateGPUEventStatus(gpuPlan.GpuID, gpuPlan.PartitionType, "Failure", fmt.Sprintf("Applying GPU settings failed with reason: %v", partition_err_reason), idx)
+			partStatus.Reason = fmt.Sprintf("Applying GPU settings failed with reason: %v", partition_err_reason)
+			setProfileStateLabel("failure")
+			partition_failed = true
+		}
+	}
+
+	// the partitions must all be up, a GPU can report CPX with only some of
+	// them enumerated
+	verificationFailed := false
+	for idx, mismatch := range verifyPartitions(gpu, plan, backend.SysfsRootFromEnv()) {
+		if mismatch == "" || partStatus.GPUStatus[idx].Status != "Success" {
+			continue
+		}
+		gpuPlan := plan.GPUs[idx]
+		log_e.Errorf("GPU ID %v: partition verification failed: %v", gpuPlan.GpuID, mismatch)
+		populateGPUEventStatus(gpuPlan.GpuID, gpuPlan.PartitionType, "Failure", fmt.Sprintf("Partition verification failed: %v", mismatch), idx)
+		partStatus.Reason = fmt.Sprintf("Partition verification failed: GPU ID %v: %v", gpuPlan.GpuID, mismatch)
+		verificationFailed = true
+	}
+	if verificationFailed {
+		generateK8sEvent(errors.New("partition verification failed"), globals.K8EventPartitionVerificationFailed, partStatus)
+		setProfileStateLabel("failure")
+		partition_failed = true
+	}
+
+	recordGPUModes(gpu, totalGPUCount)
 	if partition_failed {
 		log.Printf("Partition failed.")
 	} else {
+		if err := verifyGPUResources(ctx, gpu, plan, partition_needed); err != nil {
+			if ctx.Err() != nil {
+				// the run was superseded, the new run reports the resources
+				log.Printf("Stopped waiting for the GPU resources: %v", err)
+				return
+			}
+			log_e.Errorf("GPU resources not advertised: %v", err)
+			partStatus.Reason = fmt.Sprintf("GPU resources not advertised: %v", err)
+			generateK8sEvent(err, globals.K8EventGPUResourcesNotConverged, partStatus)
+			setProfileStateLabel("failure")
+			return
+		}
 		partStatus.FinalStatus = "Success"
 		if partition_needed {
 			partStatus.Reason = "All GPUs were successfully partitioned"
@@ -594,18 +640,40 @@ func amdSMIHelper(selectedProfile string, profile *partition_pb.GPUConfigProfile
 			generateK8sEvent(errors.New("GPU's existing partition configuration same as profile's partition config"), globals.K8EventPartitionNotNeeded, partStatus)
 		}
 
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "success")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-			return
-		}
+		setProfileStateLabel("success")
 	}
 
 }
 
-func shutDownAMDSMI() {
-	ret := C.amdsmi_shut_down()
-	if ret != C.AMDSMI_STATUS_SUCCESS {
+// reportBusyGPU marks the attempt as failed with busy and logs the pods and
+// processes keeping the GPUs busy
+func reportBusyGPU(gpu backend.GPUBackend) {
+	gpu_busy = true
+	log_e.Errorf("There might be existing pods/daemonsets on the cluster keeping the GPU resource busy, please remove them and retry. Pods using GPUs on this node: %v", formatGPUWorkloads(getNodeGPUWorkloads()))
+	log_e.Errorf("Processes holding the GPUs: %v", formatGPUProcesses(getGPUProcesses(gpu)))
+}
+
+// getNodeGPUWorkloads lists the pods of the node using GPUs, they are added
+// to the partition status once per attempt
+func getNodeGPUWorkloads() []types.GPUWorkload {
+	if nodeName == "" {
+		return []types.GPUWorkload{}
+	}
+	if partStatus.GPUWorkloads != nil {
+		return partStatus.GPUWorkloads
+	}
+	pods, err := kc.GetNodePods(nodeName)
+	if err != nil {
+		log_e.Errorf("Failed to list the pods of node %v: %v", nodeName, err)
+		return []types.GPUWorkload{}
+	}
+	partStatus.GPUWorkloads = gpuWorkloads(pods)
+	return partStatus.GPUWorkloads
+}
+
+func shutDownAMDSMI(gpu backend.GPUBackend) {
+	defer backendMu.Unlock()
+	if err := gpu.Shutdown(); err != nil {
 		log_e.Errorf("Failed to shutdown AMD SMI!")
 	} else {
 		log.Printf("AMD SMI shutdown successfully\n")
@@ -662,72 +730,77 @@ func checkInvalidPartitionType(computeType string, memoryType string) error {
 	return nil
 }
 
-func PartitionGPU(selectedProfile string) error {
+// PartitionGPU makes one partitioning attempt with the selected profile, ctx
+// bounds the wait for the device plugin
+func PartitionGPU(ctx context.Context, selectedProfile string) error {
 
 	var profile *partition_pb.GPUConfigProfile
 	var exists bool
 
 	partStatus.SelectedProfile = selectedProfile
 	partStatus.GPUStatus = nil
+	partStatus.GPUWorkloads = nil
+	partStatus.GPUProcesses = nil
 	partStatus.FinalStatus = "Failure"
+	startPartitionAttempt(selectedProfile)
+	defer finishPartitionAttempt(selectedProfile)
 	log.Println(logDivider)
 	log.Printf("Partitioning the GPU\n")
 	defer log.Println(logDivider)
-	if _, err := os.Stat(globals.JsonFilePath); os.IsNotExist(err) {
+	configPath := ConfigFilePath()
+	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		log.Printf("ConfigMap not present, please configure a configmap to proceed")
 		partStatus.Reason = "Configmap does not exist"
 		generateK8sEvent(errors.New("configmap not found"), globals.K8EventConfigMapNotPresent, partStatus)
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
 		return nil
 	} else {
-		log.Printf("Reading configmap: %v\n", globals.JsonFilePath)
+		log.Printf("Reading configmap: %v\n", configPath)
 	}
 
-	var profiles partition_pb.GPUConfigProfiles
-	file, _ := ioutil.ReadFile(globals.JsonFilePath)
-	err := json.Unmarshal(file, &profiles)
+	cfg, err := LoadConfig(configPath)
 	if err != nil {
-		log_e.Errorf("Failed to unmarshal JSON: %v", err)
-		partStatus.Reason = "Invalid JSON inside configmap"
+		log_e.Errorf("Failed to parse config: %v", err)
+		partStatus.Reason = fmt.Sprintf("Invalid JSON inside configmap: %v", err)
 		generateK8sEvent(errors.New("invalid json in configmap"), globals.K8EventInvalidJSONInConfigMap, partStatus)
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
 		return nil
 	}
 
-	profile, exists = profiles.ProfilesList[selectedProfile]
+	profile, exists = cfg.Profiles.ProfilesList[selectedProfile]
 	if exists {
 		log.Printf("Selected Profile %v found in the configmap.\n", selectedProfile)
 	} else {
 		log.Printf("Selected Profile %v not found.\n", selectedProfile)
 		partStatus.Reason = "Profile does not exist in the configmap"
 		generateK8sEvent(errors.New("profile not found"), globals.K8EventNonExistentProfile, partStatus)
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
+		return nil
+	}
+	if err := profileConfigError(cfg, selectedProfile); err != nil {
+		log_e.Errorf("Selected profile %v is incomplete: %v", selectedProfile, err)
+		partStatus.Reason = fmt.Sprintf("Invalid JSON inside configmap: %v", err)
+		generateK8sEvent(errors.New("invalid json in configmap"), globals.K8EventInvalidJSONInConfigMap, partStatus)
+		setProfileStateLabel("failure")
 		return nil
 	}
 
 	// Initialize the AMD SMI library for GPU
-	ret := C.amdsmi_init(C.AMDSMI_INIT_AMD_GPUS)
-	if ret != C.AMDSMI_STATUS_SUCCESS {
-		log_e.Errorf("Failed to initialize AMD SMI!")
+	gpu, err := initGPUBackend()
+	if err != nil {
+		log_e.Errorf("Failed to initialize AMD SMI! %v", err)
 		partStatus.Reason = "AMD-SMI API error : Failed to initialize AMD SMI!"
 		generateK8sEvent(err, globals.K8EventAMDSMIAPIFailure, partStatus)
-		err := kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
 		return nil
 	}
-	defer shutDownAMDSMI()
-	amdSMIHelper(selectedProfile, profile)
+	defer shutDownAMDSMI(gpu)
+	if count, err := gpu.GetGPUCount(); err == nil {
+		caps := discoverCapabilities(gpu, count)
+		publishCapabilities(caps)
+		reportConfigIssues(cfg, count, caps)
+	}
+	amdSMIHelper(ctx, gpu, selectedProfile, profile)
 	if partition_failed {
 		return errors.New("partition failed")
 	} else {
@@ -746,7 +819,7 @@ func printAndApplyLabelChanges(oldLabels, newLabels map[string]string) {
 				if err != nil {
 					log_e.Errorf("err: %+v", err)
 				}
-				if selectedProfile != "" {
+				if selectedProfile != "" && !takeAppliedSelection(selectedProfile) {
 					TriggerRetryLoop(selectedProfile, "nodelabel watcher")
 				}
 			}
@@ -763,6 +836,16 @@ func printAndApplyLabelChanges(oldLabels, newLabels map[string]string) {
 	}
 }
 
+// applyPlanOnlyChange re-runs the selected profile when the plan-only
+// annotation is toggled, either publishing its plan or applying it
+func applyPlanOnlyChange(labels, annotations map[string]string) {
+	selectedProfile := labels[globals.LabelKey]
+	log.Printf("Annotation %v changed to %q", globals.PlanOnlyAnnotationKey, annotations[globals.PlanOnlyAnnotationKey])
+	if selectedProfile != "" {
+		TriggerRetryLoop(selectedProfile, "node annotation watcher")
+	}
+}
+
 func NodeLabelWatcher() {
 
 	nodeInformer := kc.GetNodeInformer(nodeName)
@@ -775,6 +858,9 @@ func NodeLabelWatcher() {
 			if !reflect.DeepEqual(oldNode.Labels, newNode.Labels) {
 				printAndApplyLabelChanges(oldNode.Labels, newNode.Labels)
 			}
+			if oldNode.Annotations[globals.PlanOnlyAnnotationKey] != newNode.Annotations[globals.PlanOnlyAnnotationKey] {
+				applyPlanOnlyChange(newNode.Labels, newNode.Annotations)
+			}
 		},
 	})
 
@@ -794,6 +880,9 @@ func NodeLabelWatcher() {
 	// Wait for the informer to sync
 	if !cache.WaitForCacheSync(stopCh, nodeInformer.HasSynced) {
 		log_e.Errorf("Failed to sync informers")
+		setComponentHealth(componentNodeLabelWatcher, errors.New("node informer failed to sync"))
+	} else {
+		setComponentHealth(componentNodeLabelWatcher, nil)
 	}
 
 	log.Print("Node Informer started and will run for 100 seconds.")
@@ -803,26 +892,64 @@ func NodeLabelWatcher() {
 
 func RetryPartition(ctx context.Context, selectedProfile string) {
 	defer wg.Done()
+	defer func() {
+		// a cancelled loop is superseded by the loop of the new trigger
+		if ctx.Err() == nil {
+			MarkReady()
+		}
+	}()
+	partitionRunning.Store(true)
+	defer partitionRunning.Store(false)
+	defer partitionRetryCount.Set(0)
+	// a dcmctl apply in flight is waited for
+	lock, err := waitRunLock(ctx)
+	if err != nil {
+		log.Printf("Aborting retry loop: %v", err)
+		return
+	}
+	defer lock.Close()
 	expiration := time.Now().Add(30 * time.Minute)
 	count := 1
-	var services partition_pb.GPUClientSystemdServices
-	file, _ := ioutil.ReadFile(globals.JsonFilePath)
-	err := json.Unmarshal(file, &services)
+	if planOnlyRequested() {
+		publishPartitionPlan(selectedProfile)
+		releaseJournal()
+		return
+	}
+
+	cfg, err := LoadConfig(ConfigFilePath())
 	if err != nil {
-		log_e.Errorf("Failed to unmarshal JSON: %v", err)
-		partStatus.Reason = "Invalid JSON inside configmap"
+		log_e.Errorf("Failed to parse config: %v", err)
+		partStatus.Reason = fmt.Sprintf("Invalid JSON inside configmap: %v", err)
 		generateK8sEvent(errors.New("invalid json in configmap"), globals.K8EventInvalidJSONInConfigMap, partStatus)
-		err = kc.AddNodeLabel(nodeName, "dcm.amd.com/gpu-config-profile-state", "failure")
-		if err != nil {
-			log.Printf("Error adding status node label: %s\n", err.Error())
-		}
+		setProfileStateLabel("failure")
+		// the services of a resumed run are started again
+		releaseJournal()
 		return
 	}
-
-	serviceList := []string{}
-	if services.List != nil {
-		serviceList = services.List.Names
+	serviceList := cfg.Services
+	beginJournal(selectedProfile, serviceList)
+	tainted, proceed := prepareNodeTaint(selectedProfile)
+	if !proceed {
+		// nothing was stopped by this run, but a resumed run has its
+		// services stopped
+		releaseJournal()
+		return
 	}
+	defer func() {
+		if tainted {
+			removeNodeTaint()
+		}
+	}()
+	drainPolicy := drainPolicyFromEnv()
+	drained := false
+	// set when the node was cordoned by this loop, it is uncordoned once the
+	// loop ends
+	cordoned := false
+	defer func() {
+		if cordoned {
+			uncordonNode()
+		}
+	}()
 
 	for {
 		select {
@@ -837,16 +964,34 @@ func RetryPartition(ctx context.Context, selectedProfile string) {
 		if time.Now().After(expiration) {
 			generateK8sEvent(errors.New("partition failed"), globals.K8EventPartitionFailed, partStatus)
 			log.Println("Retry loop expired after retrying for 30 mins")
-			utils.StartServiceHandler(serviceList)
+			partitionRetryExpired.WithLabelValues(selectedProfile).Inc()
+			startServices(stoppedServices(serviceList))
+			finishJournal()
 			return
 		}
 
+		start := time.Now()
 		utils.StopServiceHandler(serviceList)
+		observePhase(phaseServiceStop, start)
 		log.Printf("Calling PartitionGPU...\n")
 
-		if err := PartitionGPU(selectedProfile); err != nil {
+		if err := PartitionGPU(ctx, selectedProfile); err != nil {
 			log.Printf("Error occurred in PartitionGPU: %v\n", err)
+			if gpu_busy && drainPolicy == globals.DrainPolicyDrain && !drained && nodeName != "" {
+				drained = true
+				var drainErr error
+				cordoned, drainErr = drainNode(ctx)
+				if drainErr == nil {
+					createK8sEvent(nil, globals.K8EventNodeDrained, fmt.Sprintf("Node drained for partitioning with profile %v", selectedProfile))
+					log.Println("Node drained, retrying right away")
+					continue
+				}
+				log_e.Errorf("Failed to drain the node: %v", drainErr)
+				createK8sEvent(drainErr, globals.K8EventNodeDrainFailure, drainErr.Error())
+			}
 			log.Println("Waiting for 1 minute before retrying...")
+			partitionRetries.WithLabelValues(selectedProfile).Inc()
+			partitionRetryCount.Inc()
 			if count == 1 {
 				count = count + 1
 				partStatus.FinalStatus = "Partition failed, retrying."
@@ -863,12 +1008,20 @@ func RetryPartition(ctx context.Context, selectedProfile string) {
 			}
 		} else {
 			log.Println("PartitionGPU executed successfully")
-			utils.StartServiceHandler(serviceList)
+			startServices(stoppedServices(serviceList))
+			finishJournal()
 			return
 		}
 	}
 }
 
+// startServices restarts the systemd services stopped for partitioning
+func startServices(serviceList []string) {
+	start := time.Now()
+	utils.StartServiceHandler(serviceList)
+	observePhase(phaseServiceStart, start)
+}
+
 // Worker function to handle retry signals
 func Worker() {
 	for prof := range retryCh {
@@ -890,12 +1043,16 @@ func Worker() {
 	}
 }
 
-func TriggerRetryLoop(selectedProfile string, funcname string) {
+// TriggerRetryLoop reports whether the trigger was queued, it is ignored
+// while another one is pending
+func TriggerRetryLoop(selectedProfile string, funcname string) bool {
 	select {
 	case retryCh <- selectedProfile: // Signal a retry request
 		log.Printf("Triggering new retry loop from %s\n", funcname)
+		return true
 	default:
 		log.Println("Retry loop already pending, ignoring trigger")
+		return false
 	}
 }
 
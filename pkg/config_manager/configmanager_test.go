@@ -0,0 +1,950 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	utils "github.com/ROCm/device-config-manager/pkg/partition/utils"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcstatus "google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestMain points the sysfs root at an empty tree so partition verification
+// of the simulated GPUs does not read the KFD topology of the host
+func TestMain(m *testing.M) {
+	root, err := os.MkdirTemp("", "dcm-sysfs")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(backend.SysfsRootEnv, root)
+	code := m.Run()
+	os.RemoveAll(root)
+	os.Exit(code)
+}
+
+func newTestProfile() *partition_pb.GPUConfigProfile {
+	return &partition_pb.GPUConfigProfile{
+		Filters: &partition_pb.SkippedGPUs{Id: []uint32{3}},
+		Profiles: []*partition_pb.ProfileConfig{
+			{ComputePartition: "CPX", MemoryPartition: "NPS1", NumGPUsAssigned: 2},
+			{ComputePartition: "DPX", MemoryPartition: "NPS1", NumGPUsAssigned: 1},
+		},
+	}
+}
+
+func TestAMDSMIHelperWithSimulator(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS4"})
+	assert.NoError(t, sim.Init())
+
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.False(t, partition_failed)
+	assert.Equal(t, "Success", partStatus.FinalStatus)
+	assert.Len(t, partStatus.GPUStatus, 3)
+
+	expected := map[int][2]string{0: {"CPX", "NPS1"}, 1: {"CPX", "NPS1"}, 2: {"DPX", "NPS1"}, 3: {"CPX", "NPS4"}}
+	for id, modes := range expected {
+		assert.Equal(t, modes[0], getCurrentGPUComputePartition(sim, id), "gpu %d", id)
+		assert.Equal(t, modes[1], getCurrentGPUMemoryPartition(sim, id), "gpu %d", id)
+	}
+
+	// applying the same profile again is a no-op
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.False(t, partition_failed)
+	assert.Equal(t, "Partition not required", partStatus.GPUStatus[0].Message)
+}
+
+func TestAMDSMIHelperBusyGPU(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS4", BusyGPUs: map[int]int{1: -1}})
+	assert.NoError(t, sim.Init())
+
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.True(t, partition_failed)
+	assert.Equal(t, "Failure", partStatus.GPUStatus[1].Status)
+	assert.Equal(t, "NPS4", getCurrentGPUMemoryPartition(sim, 1))
+}
+
+func TestAMDSMIHelperUnsupportedMode(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{
+		NumGPUs:                    4,
+		SupportedComputePartitions: []string{"SPX", "CPX"},
+		SupportedMemoryPartitions:  []string{"NPS1", "NPS4"},
+	})
+	assert.NoError(t, sim.Init())
+
+	caps := discoverCapabilities(sim, 4)
+	issues := checkCapabilities("test", newTestProfile(), 4, caps)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[1].computePartition", issues[0].Path)
+	assert.Contains(t, issues[0].Message, "GPU IDs [2]")
+
+	// the profile is rejected before any GPU is changed
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.Contains(t, partStatus.Reason, "compute type DPX is not supported")
+	for id := range 4 {
+		assert.Equal(t, "SPX", getCurrentGPUComputePartition(sim, id), "gpu %d", id)
+		assert.Equal(t, "NPS1", getCurrentGPUMemoryPartition(sim, id), "gpu %d", id)
+	}
+}
+
+func TestPartitionCompatibility(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4})
+	assert.NoError(t, sim.Init())
+	caps := discoverCapabilities(sim, 4)
+	assert.Equal(t, "MI300X", caps[0].Model)
+
+	assert.Empty(t, checkCapabilities("test", newTestProfile(), 4, caps))
+
+	// DPX only pairs with NPS1 or NPS2 on MI300X
+	profile := newTestProfile()
+	for _, p := range profile.Profiles {
+		p.MemoryPartition = "NPS4"
+	}
+	issues := checkCapabilities("test", profile, 4, caps)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[1].computePartition", issues[0].Path)
+	assert.Contains(t, issues[0].Message, "compute types valid with NPS4 are [CPX]")
+	assert.Equal(t, globals.K8EventInvalidComputeType, validationEvent(issuesError(issues)))
+
+	for _, p := range profile.Profiles {
+		p.ComputePartition = "SPX"
+		p.MemoryPartition = "NPS2"
+	}
+	issues = checkCapabilities("test", profile, 4, caps)
+	assert.Len(t, issues, 2)
+	assert.Equal(t, "GPU IDs [0 1]: compute type SPX cannot be used with memory type NPS2 on MI300X, compute types valid with NPS2 are [DPX]", issues[0].Message)
+
+	// NPS4 does not exist on MI300A, found through the device id
+	assert.Equal(t, "MI300A", backend.ASICModel(backend.ASICInfo{DeviceID: 0x74a0}))
+	field, _ := checkCompatibility("MI300A", "CPX", "NPS4")
+	assert.Equal(t, "memoryPartition", field)
+	field, _ = checkCompatibility("unknown", "CPX", "NPS4")
+	assert.Equal(t, "", field)
+}
+
+func TestTPXPartition(t *testing.T) {
+	sim, dir := newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "SPX", MemoryPartition: "NPS1",
+		MarketName: "AMD Instinct MI300A", SupportedComputePartitions: []string{"SPX", "TPX", "CPX"}, SupportedMemoryPartitions: []string{"NPS1"}})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"gpu-config-profiles": {"test": {"profiles": [
+  {"computePartition": "TPX", "memoryPartition": "NPS1", "numGPUsAssigned": 4}]}}}`), 0644))
+
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+	assert.Equal(t, "Success", partStatus.FinalStatus)
+	assert.NoError(t, sim.Init())
+	for id := range 4 {
+		compute, err := sim.GetComputePartition(id)
+		assert.NoError(t, err)
+		assert.Equal(t, "TPX", compute)
+	}
+}
+
+func TestAcceleratorProfile(t *testing.T) {
+	cfg, err := parseConfig([]byte(`{"gpu-config-profiles": {"accel": {"skippedGPUs": {"ids": [3]}, "profiles": [
+  {"computePartition": "CPX", "memoryPartition": "NPS1", "numGPUsAssigned": 2, "acceleratorProfile": {"type": "CPX"}},
+  {"computePartition": "DPX", "memoryPartition": "NPS1", "numGPUsAssigned": 1, "acceleratorProfile": {"index": 1}}]}}}`))
+	assert.NoError(t, err)
+	profile := cfg.Profiles.ProfilesList["accel"]
+
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4})
+	assert.NoError(t, sim.Init())
+	amdSMIHelper(context.Background(), sim, "accel", profile)
+	assert.False(t, partition_failed)
+	assert.Equal(t, "CPX", getCurrentGPUComputePartition(sim, 0))
+	assert.Equal(t, "DPX", getCurrentGPUComputePartition(sim, 2))
+
+	status := partStatus.GPUStatus[0].AcceleratorProfile
+	assert.NotNil(t, status)
+	assert.Equal(t, 3, status.Index)
+	assert.Len(t, status.Partitions, 8)
+	assert.Equal(t, 1, status.Partitions[7].Resources["XCC"])
+	assert.Equal(t, uint64(24<<30), status.Partitions[7].MemoryBytes)
+	assert.Len(t, partStatus.GPUStatus[2].AcceleratorProfile.Partitions, 2)
+
+	// the index must point at a profile of the requested compute type
+	profile.Profiles[1].AcceleratorProfile.Index = 2
+	issues := checkCapabilities("accel", profile, 4, discoverCapabilities(sim, 4))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.accel.profiles[1].acceleratorProfile", issues[0].Path)
+	assert.Contains(t, issues[0].Message, "accelerator profile 2 is of type QPX, not DPX")
+
+	profile.Profiles[0].AcceleratorProfile.Type = "SPX"
+	assert.Error(t, validateProfile("accel", profile, 4))
+}
+
+func TestPowerCap(t *testing.T) {
+	profile := newTestProfile()
+	profile.Profiles[0].PowerCapWatts = 500
+
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+	assert.NoError(t, sim.Init())
+	plan, err := buildPartitionPlan(sim, "test", profile, 4)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(750), plan.GPUs[0].CurrentPowerCapWatts)
+	assert.True(t, plan.GPUs[0].SettingsChange())
+	assert.False(t, plan.GPUs[2].SettingsChange())
+
+	amdSMIHelper(context.Background(), sim, "test", profile)
+	assert.False(t, partition_failed)
+	assert.Equal(t, uint32(500), partStatus.GPUStatus[0].PowerCapWatts)
+	assert.Equal(t, uint32(500), partStatus.GPUStatus[1].PowerCapWatts)
+
+	// the cap must be within the range reported by the GPU
+	profile.Profiles[0].PowerCapWatts = 900
+	issues := checkCapabilities("test", profile, 4, discoverCapabilities(sim, 4))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[0].powerCapWatts", issues[0].Path)
+}
+
+func TestClocksAndPerfLevel(t *testing.T) {
+	profile := newTestProfile()
+	profile.Profiles[0].PerfLevel = "DETERMINISM"
+	profile.Profiles[0].DeterminismClockMHz = 1800
+	profile.Profiles[1].PerfLevel = "MANUAL"
+	profile.Profiles[1].Clocks = []*partition_pb.ClockRange{{Type: "GFX", MinMHz: 1000, MaxMHz: 1900}, {Type: "MEM", MaxMHz: 1200}}
+	assert.Empty(t, checkProfile("test", profile, 4))
+
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+	assert.NoError(t, sim.Init())
+	amdSMIHelper(context.Background(), sim, "test", profile)
+	assert.False(t, partition_failed)
+	assert.Equal(t, "DETERMINISM", partStatus.GPUStatus[0].PerfLevel)
+	assert.Equal(t, "MANUAL", partStatus.GPUStatus[2].PerfLevel)
+	assert.Equal(t, types.ClockRange{MinMHz: 1000, MaxMHz: 1900}, partStatus.GPUStatus[2].Clocks["GFX"])
+	assert.Equal(t, types.ClockRange{MinMHz: 900, MaxMHz: 1200}, partStatus.GPUStatus[2].Clocks["MEM"])
+
+	// GPUs already at the requested values are left alone
+	plan, err := buildPartitionPlan(sim, "test", profile, 4)
+	assert.NoError(t, err)
+	for _, gpuPlan := range plan.GPUs {
+		assert.False(t, gpuPlan.SettingsChange())
+	}
+	profile.Profiles[0].DeterminismClockMHz = 1700
+	plan, err = buildPartitionPlan(sim, "test", profile, 4)
+	assert.NoError(t, err)
+	assert.True(t, plan.GPUs[0].PerfLevelChange)
+
+	profile.Profiles[1].Clocks[0].MaxMHz = 2500
+	issues := checkCapabilities("test", profile, 4, discoverCapabilities(sim, 4))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[1].clocks[0]", issues[0].Path)
+
+	profile.Profiles[0].DeterminismClockMHz = 0
+	issues = checkProfile("test", profile, 4)
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[0].determinismClockMHz", issues[0].Path)
+}
+
+func TestPowerProfileAndIsolation(t *testing.T) {
+	isolated := true
+	profile := newTestProfile()
+	profile.Profiles[0].PowerProfile = "COMPUTE"
+	profile.Profiles[0].ProcessIsolation = &isolated
+
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+	assert.NoError(t, sim.Init())
+	amdSMIHelper(context.Background(), sim, "test", profile)
+	assert.False(t, partition_failed)
+	assert.Equal(t, "COMPUTE", partStatus.GPUStatus[1].PowerProfile)
+	assert.Equal(t, &isolated, partStatus.GPUStatus[1].ProcessIsolation)
+	assert.Empty(t, partStatus.GPUStatus[2].PowerProfile)
+	assert.Nil(t, partStatus.GPUStatus[2].ProcessIsolation)
+
+	plan, err := buildPartitionPlan(sim, "test", profile, 4)
+	assert.NoError(t, err)
+	assert.False(t, plan.GPUs[0].SettingsChange())
+
+	// isolation of an SPX GPU does not carry over to its CPX partitions
+	sim = backend.NewSimBackend(backend.SimConfig{NumGPUs: 4})
+	assert.NoError(t, sim.Init())
+	assert.NoError(t, sim.SetProcessIsolation(0, true))
+	amdSMIHelper(context.Background(), sim, "test", profile)
+	assert.False(t, partition_failed)
+	assert.Equal(t, &isolated, partStatus.GPUStatus[0].ProcessIsolation)
+	isolation, err := sim.GetProcessIsolation(0)
+	assert.NoError(t, err)
+	assert.True(t, isolation)
+
+	profile.Profiles[0].PowerProfile = "VR"
+	issues := checkCapabilities("test", profile, 4, discoverCapabilities(sim, 4))
+	assert.Len(t, issues, 1)
+	assert.Equal(t, "gpu-config-profiles.test.profiles[0].powerProfile", issues[0].Path)
+}
+
+func TestBuildPartitionPlan(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+	assert.NoError(t, sim.Init())
+
+	plan, err := buildPartitionPlan(sim, "test", newTestProfile(), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", plan.SelectedProfile)
+	assert.Len(t, plan.GPUs, 3)
+	assert.True(t, plan.PartitionNeeded())
+
+	assert.Equal(t, 0, plan.GPUs[0].GpuID)
+	assert.False(t, plan.GPUs[0].ComputeChange)
+	assert.False(t, plan.GPUs[0].DriverReloadNeeded)
+	assert.Equal(t, 2, plan.GPUs[2].GpuID)
+	assert.Equal(t, "CPX", plan.GPUs[2].CurrentCompute)
+	assert.Equal(t, "DPX", plan.GPUs[2].TargetCompute)
+	assert.True(t, plan.GPUs[2].ComputeChange)
+	assert.False(t, plan.GPUs[2].MemoryChange)
+
+	// computing the plan does not touch the GPUs
+	assert.Equal(t, "CPX", getCurrentGPUComputePartition(sim, 2))
+}
+
+func TestValidateProfilesReportsAllIssues(t *testing.T) {
+	profiles := &partition_pb.GPUConfigProfiles{
+		ProfilesList: map[string]*partition_pb.GPUConfigProfile{
+			"valid": newTestProfile(),
+			"broken": {
+				Filters: &partition_pb.SkippedGPUs{Id: []uint32{5, 5}},
+				Profiles: []*partition_pb.ProfileConfig{
+					{ComputePartition: "CPX", MemoryPartition: "NPS4", NumGPUsAssigned: 1},
+					{ComputePartition: "XPX", MemoryPartition: "NPS1", NumGPUsAssigned: 0},
+				},
+			},
+		},
+	}
+
+	issues := ValidateProfiles(profiles, 4)
+	paths := map[string]string{}
+	for _, issue := range issues {
+		paths[issue.Path] = issue.Severity
+	}
+	assert.Equal(t, map[string]string{
+		"gpu-config-profiles.broken.skippedGPUs.ids[0]":           SeverityError,
+		"gpu-config-profiles.broken.skippedGPUs.ids[1]":           SeverityError,
+		"gpu-config-profiles.broken.profiles[1].computePartition": SeverityError,
+		"gpu-config-profiles.broken.profiles[1].memoryPartition":  SeverityError,
+		"gpu-config-profiles.broken.profiles[1].numGPUsAssigned":  SeverityWarning,
+		"gpu-config-profiles.broken.profiles":                     SeverityError,
+	}, paths)
+	assert.True(t, HasErrors(issues))
+
+	assert.NoError(t, validateProfile("valid", newTestProfile(), 4))
+	assert.Error(t, validateProfile("broken", profiles.ProfilesList["broken"], 4))
+}
+
+func TestParseConfigStrict(t *testing.T) {
+	valid := `{
+  "gpu-config-profiles": {
+    "default": {
+      "skippedGPUs": {"ids": [3]},
+      "profiles": [{"computePartition": "CPX", "memoryPartition": "NPS4", "numGPUsAssigned": 3}]
+    },
+    "incomplete": {
+      "profiles": [{"computePartition": "CPX", "memoryPartition": "NPS4"}]
+    }
+  },
+  "gpuClientSystemdServices": {"names": ["amd-metrics-exporter"]}
+}`
+	cfg, err := parseConfig([]byte(valid))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"amd-metrics-exporter"}, cfg.Services)
+	assert.Len(t, cfg.Profiles.ProfilesList, 2)
+	assert.NoError(t, profileConfigError(cfg, "default"))
+	assert.EqualError(t, profileConfigError(cfg, "incomplete"),
+		"line 8 column 20: gpu-config-profiles.incomplete.profiles[0].numGPUsAssigned: missing required field")
+
+	tests := map[string]string{
+		// misspelled numGPUsAssigned
+		`{"gpu-config-profiles": {"a": {"profiles": [
+  {"computePartition": "CPX", "memoryPartition": "NPS1", "numGPUAssigned": 8}]}}}`: `line 2 column 58: gpu-config-profiles.a.profiles[0].numGPUAssigned: unknown field "numGPUAssigned"`,
+		`{"gpu-config-profiles": {"a": {"profiles": [],}}}`:                        "line 1 column 47: invalid character '}' looking for beginning of object key string",
+		`{"gpu-config-profiles": {"a": {"profiles": [{"numGPUsAssigned": "8"}]}}}`: "line 1 column 68: gpu-config-profiles.a.profiles[0].numGPUsAssigned: cannot use string value as uint32",
+		`{"gpuClientSystemdServices": {"names": []}}`:                              "line 1 column 1: gpu-config-profiles: missing required field",
+	}
+	for config, expected := range tests {
+		_, err := parseConfig([]byte(config))
+		assert.EqualError(t, err, expected, config)
+	}
+}
+
+func TestParseYAMLConfig(t *testing.T) {
+	valid := `# comments are allowed
+gpu-config-profiles:
+  default:
+    skippedGPUs:
+      ids: [3]
+    profiles:
+      - computePartition: CPX
+        memoryPartition: NPS4
+        numGPUsAssigned: 3
+  incomplete:
+    profiles:
+      - computePartition: CPX
+        memoryPartition: NPS4
+gpuClientSystemdServices:
+  names: [amd-metrics-exporter]
+`
+	cfg, err := parseYAMLConfig([]byte(valid))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"amd-metrics-exporter"}, cfg.Services)
+	assert.Equal(t, []uint32{3}, cfg.Profiles.ProfilesList["default"].Filters.Id)
+	assert.Equal(t, uint32(3), cfg.Profiles.ProfilesList["default"].Profiles[0].NumGPUsAssigned)
+	assert.NoError(t, profileConfigError(cfg, "default"))
+	assert.EqualError(t, profileConfigError(cfg, "incomplete"),
+		"line 12 column 9: gpu-config-profiles.incomplete.profiles[0].numGPUsAssigned: missing required field")
+
+	cfg, err = parseYAMLConfig([]byte("gpu-config-profiles:\n  a:\n    profiles:\n      - computePartition: CPX\n        memoryPartition: NPS1\n        numGPUsAssigned: 8\n        processIsolation: true\n"))
+	assert.NoError(t, err)
+	assert.True(t, cfg.Profiles.ProfilesList["a"].Profiles[0].GetProcessIsolation())
+
+	tests := map[string]string{
+		"gpu-config-profiles:\n  a:\n    profiles:\n      - numGPUAssigned: 8\n":      `line 4 column 9: gpu-config-profiles.a.profiles[0].numGPUAssigned: unknown field "numGPUAssigned"`,
+		"gpu-config-profiles:\n  a:\n    profiles:\n      - numGPUsAssigned: eight\n": "line 4 column 26: gpu-config-profiles.a.profiles[0].numGPUsAssigned: cannot use string value as uint32",
+		"gpu-config-profiles:\n  a:\n    profiles:\n      - processIsolation: yes\n":  "line 4 column 27: gpu-config-profiles.a.profiles[0].processIsolation: cannot use string value as bool",
+		"gpu-config-profiles:\n  a: [\n":                                              "line 2: did not find expected node content",
+	}
+	for config, expected := range tests {
+		_, err := parseYAMLConfig([]byte(config))
+		assert.EqualError(t, err, expected, config)
+	}
+}
+
+// newStandaloneTest runs DCM in standalone mode against a simulator and a
+// config file holding the test profile
+func newStandaloneTest(t *testing.T, cfg backend.SimConfig) (*backend.SimBackend, string) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.json")
+	assert.NoError(t, os.WriteFile(configPath, []byte(`{"gpu-config-profiles": {"test": {"skippedGPUs": {"ids": [3]}, "profiles": [
+  {"computePartition": "CPX", "memoryPartition": "NPS1", "numGPUsAssigned": 2},
+  {"computePartition": "DPX", "memoryPartition": "NPS1", "numGPUsAssigned": 1}]}}}`), 0644))
+	SetConfigFilePath(configPath)
+	EnableStandaloneMode(StandaloneConfig{Profile: "test", StateFile: filepath.Join(dir, "state.json")})
+	sim := backend.NewSimBackend(cfg)
+	SetGPUBackend(sim)
+	t.Cleanup(func() {
+		standalone = nil
+		SetConfigFilePath("")
+		SetGPUBackend(nil)
+	})
+	return sim, dir
+}
+
+func TestEnvHelpers(t *testing.T) {
+	t.Setenv(globals.ReconcileIntervalEnv, "0")
+	assert.Equal(t, time.Duration(0), reconcileIntervalFromEnv())
+	t.Setenv(globals.ReconcileIntervalEnv, "-1m")
+	assert.Equal(t, globals.DefaultReconcileInterval, reconcileIntervalFromEnv())
+	t.Setenv(globals.DrainTimeoutEnv, "0")
+	assert.Equal(t, globals.DefaultDrainTimeout, drainTimeoutFromEnv())
+	t.Setenv(globals.DrainPolicyEnv, "Drain")
+	assert.Equal(t, globals.DrainPolicyDrain, drainPolicyFromEnv())
+	t.Setenv(globals.DriftPolicyEnv, "revert")
+	assert.Equal(t, globals.DriftPolicyReport, driftPolicyFromEnv())
+}
+
+func TestDriftReconciler(t *testing.T) {
+	sim, dir := newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+	assert.Equal(t, "success", currentProfileState())
+	checkDrift(globals.DriftPolicyReport)
+	assert.Equal(t, "success", currentProfileState())
+
+	// a manual change moves the node to drifted
+	assert.NoError(t, sim.Init())
+	assert.NoError(t, sim.SetComputePartition(2, "SPX"))
+	checkDrift(globals.DriftPolicyReport)
+	assert.Equal(t, "drifted", currentProfileState())
+	state, err := ReadNodeState(filepath.Join(dir, "state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, globals.K8EventPartitionDrifted, state.LastEvent)
+	assert.Len(t, state.PartitionStatus.GPUStatus, 1)
+	assert.Equal(t, 2, state.PartitionStatus.GPUStatus[0].GpuID)
+
+	// the reapply policy queues a new partition run
+	checkDrift(globals.DriftPolicyReapply)
+	assert.Equal(t, "test", <-retryCh)
+
+	assert.NoError(t, sim.Init())
+	assert.NoError(t, sim.SetComputePartition(2, "DPX"))
+	checkDrift(globals.DriftPolicyReport)
+	assert.Equal(t, "success", currentProfileState())
+}
+
+func TestDriftCheckDuringPartition(t *testing.T) {
+	newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1"})
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+
+	// a query waits for the run holding the backend instead of shutting it down
+	gpu, err := initGPUBackend()
+	assert.NoError(t, err)
+	queried := make(chan struct{})
+	go func() {
+		defer close(queried)
+		_, err := GetCurrentPartitions()
+		assert.NoError(t, err)
+	}()
+	time.Sleep(50 * time.Millisecond)
+	_, err = gpu.GetGPUCount()
+	assert.NoError(t, err)
+	shutDownAMDSMI(gpu)
+	<-queried
+
+	var checks sync.WaitGroup
+	checks.Add(1)
+	go func() {
+		defer checks.Done()
+		for range 20 {
+			checkDrift(globals.DriftPolicyReport)
+			_, err := GetCurrentPartitions()
+			assert.NoError(t, err)
+		}
+	}()
+	for range 5 {
+		assert.NoError(t, PartitionGPU(context.Background(), "test"))
+	}
+	checks.Wait()
+	assert.Equal(t, "success", currentProfileState())
+}
+
+func TestPartitionMetrics(t *testing.T) {
+	// GPU 2 is busy for the first attempt only
+	newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "CPX", MemoryPartition: "NPS1", BusyGPUs: map[int]int{2: 1}})
+	partitionAttempts.Reset()
+	partitionSuccesses.Reset()
+	partitionFailures.Reset()
+	assert.Error(t, PartitionGPU(context.Background(), "test"))
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+
+	recorder := httptest.NewRecorder()
+	promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+	assert.Contains(t, body, `dcm_partition_attempts_total{profile="test"} 2`)
+	assert.Contains(t, body, `dcm_partition_successes_total{profile="test"} 1`)
+	assert.Contains(t, body, `dcm_partition_failures_total{profile="test",reason="PartitionFailure"} 1`)
+	assert.Contains(t, body, `dcm_gpu_compute_partition_info{gpu_id="0",partition="CPX"} 1`)
+	assert.Contains(t, body, `dcm_partition_phase_duration_seconds_count{phase="compute_partition"}`)
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	t.Cleanup(func() {
+		componentHealth = map[string]error{}
+		ready = false
+	})
+	probe := func(handler http.HandlerFunc) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler(recorder, httptest.NewRequest("GET", "/", nil))
+		return recorder
+	}
+
+	setComponentHealth(componentConfigWatcher, nil)
+	assert.Equal(t, http.StatusOK, probe(healthzHandler).Code)
+	setComponentHealth(componentConfigWatcher, errWatcherStopped)
+	recorder := probe(healthzHandler)
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), `"config-file-watcher":"watcher stopped"`)
+
+	assert.Equal(t, http.StatusServiceUnavailable, probe(readyzHandler).Code)
+	MarkReady()
+	assert.Equal(t, http.StatusOK, probe(readyzHandler).Code)
+}
+
+func TestGRPCService(t *testing.T) {
+	_, stateDir := newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "SPX", MemoryPartition: "NPS1"})
+	// unix socket paths are limited to 108 bytes, t.TempDir can be longer
+	dir, err := os.MkdirTemp("", "dcm")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "dcm.sock")
+	lis, err := listenUnix(socket)
+	assert.NoError(t, err)
+	server := newGRPCServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	assert.NoError(t, err)
+	defer conn.Close()
+	client := partition_pb.NewDeviceConfigManagerClient(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	profiles, err := client.ListProfiles(ctx, &partition_pb.ListProfilesRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"test"}, profiles.Names)
+
+	_, err = client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "missing"})
+	assert.Error(t, err)
+	plan, err := client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "test", DryRun: true})
+	assert.NoError(t, err)
+	assert.False(t, plan.Started)
+	assert.Len(t, plan.Plan, 3)
+	assert.True(t, plan.Plan[0].ComputeChange)
+
+	// the backend is held by a partition run
+	gpu, err := initGPUBackend()
+	assert.NoError(t, err)
+	_, err = client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "test", DryRun: true})
+	assert.Equal(t, codes.Unavailable, grpcstatus.Code(err))
+	shutDownAMDSMI(gpu)
+
+	stream, err := client.WatchEvents(ctx, &partition_pb.WatchEventsRequest{})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		watchersMu.Lock()
+		defer watchersMu.Unlock()
+		return len(watchers) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+	gpuEvents := 0
+	for {
+		event, err := stream.Recv()
+		if !assert.NoError(t, err) {
+			break
+		}
+		if event.GPU != nil {
+			assert.Equal(t, "Success", event.GPU.Status)
+			gpuEvents++
+			continue
+		}
+		assert.Equal(t, globals.K8EventSuccessfullyPartitioned, event.Event)
+		break
+	}
+	assert.Equal(t, 3, gpuEvents)
+
+	status, err := client.GetStatus(ctx, &partition_pb.GetStatusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Success", status.FinalStatus)
+	assert.Equal(t, "success", status.ProfileState)
+	assert.Len(t, status.GPUStatus, 3)
+
+	// the -profile flag pins the selection
+	standalone.Profile = "other"
+	_, err = client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "test"})
+	assert.Equal(t, codes.FailedPrecondition, grpcstatus.Code(err))
+	standalone.Profile = "test"
+	started, err := client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "test"})
+	assert.NoError(t, err)
+	assert.True(t, started.Started)
+	assert.Equal(t, "test", <-retryCh)
+
+	// the profile file is updated before the run is triggered, the profile
+	// file watcher skips the change
+	standalone.Profile = ""
+	standalone.ProfileFile = filepath.Join(stateDir, "profile")
+	started, err = client.Apply(ctx, &partition_pb.ApplyRequest{Profile: "test"})
+	assert.NoError(t, err)
+	assert.True(t, started.Started)
+	assert.Equal(t, "test", <-retryCh)
+	selected, err := GetPartitionProfile()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", selected)
+	assert.True(t, takeAppliedSelection("test"))
+	assert.False(t, takeAppliedSelection("test"))
+}
+
+func TestPartitionJournal(t *testing.T) {
+	_, dir := newStandaloneTest(t, backend.SimConfig{NumGPUs: 4, ComputePartition: "SPX", MemoryPartition: "NPS1"})
+	journalFile := filepath.Join(dir, "journal.json")
+	t.Setenv(globals.JournalFileEnv, journalFile)
+	t.Cleanup(func() {
+		journal = nil
+		utils.PreStateHook = nil
+		utils.CleanupPreState()
+	})
+
+	// the journal follows the run until the services are started again
+	beginJournal("test", []string{"gpuagent"})
+	utils.PreStateHook(utils.ServicePreState{Name: "gpuagent.service", State: "inactive"})
+	assert.NoError(t, PartitionGPU(context.Background(), "test"))
+	j, err := readJournal()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", j.Profile)
+	assert.Equal(t, "inactive", j.PreStates["gpuagent.service"].State)
+	assert.Len(t, j.GPUStatus, 3)
+	finishJournal()
+	assert.NoFileExists(t, journalFile)
+
+	// a run interrupted with its profile still selected is resumed
+	beginJournal("test", []string{"gpuagent"})
+	utils.PreStateHook(utils.ServicePreState{Name: "gpuagent.service", State: "inactive"})
+	journal = nil
+	RecoverJournal("test")
+	assert.FileExists(t, journalFile)
+	assert.Equal(t, "inactive", utils.PreStateDB["gpuagent.service"].State)
+	state, err := ReadNodeState(filepath.Join(dir, "state.json"))
+	assert.NoError(t, err)
+	assert.Equal(t, globals.K8EventPartitionInterrupted, state.LastEvent)
+
+	// a superseding run keeps the services stopped by the run it replaced
+	beginJournal("test", []string{"amd-metrics-exporter"})
+	assert.Equal(t, []string{"amd-metrics-exporter.service", "gpuagent.service"}, journalServices(journal))
+
+	// a resumed run that cannot load the config starts the services again
+	utils.PreStateDB["gpuagent.service"] = utils.ServicePreState{Name: "gpuagent.service", State: "inactive"}
+	badConfig := filepath.Join(dir, "bad.json")
+	assert.NoError(t, os.WriteFile(badConfig, []byte("{"), 0644))
+	SetConfigFilePath(badConfig)
+	wg.Add(1)
+	RetryPartition(context.Background(), "test")
+	assert.NoFileExists(t, journalFile)
+	assert.Empty(t, utils.PreStateDB)
+	assert.Nil(t, journal)
+	SetConfigFilePath(filepath.Join(dir, "config.json"))
+
+	// otherwise the services are started and the journal dropped
+	beginJournal("test", []string{"gpuagent"})
+	utils.PreStateHook(utils.ServicePreState{Name: "gpuagent.service", State: "inactive"})
+	journal = nil
+	RecoverJournal("other")
+	assert.NoFileExists(t, journalFile)
+	assert.Empty(t, utils.PreStateDB)
+}
+
+func TestGPUPodsToEvict(t *testing.T) {
+	controller := true
+	gpuPod := func(name string, resourceName v1.ResourceName) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec: v1.PodSpec{Containers: []v1.Container{{Resources: v1.ResourceRequirements{
+				Limits: v1.ResourceList{resourceName: resource.MustParse("1")},
+			}}}},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	cpx := gpuPod("cpx", "amd.com/cpx_nps4")
+	cpu := gpuPod("cpu", v1.ResourceCPU)
+	done := gpuPod("done", "amd.com/gpu")
+	done.Status.Phase = v1.PodSucceeded
+	ds := gpuPod("ds", "amd.com/gpu")
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: "DaemonSet", Name: "exporter", Controller: &controller}}
+	static := gpuPod("static", "amd.com/gpu")
+	static.Annotations = map[string]string{v1.MirrorPodAnnotationKey: "x"}
+
+	evict := gpuPodsToEvict([]v1.Pod{gpuPod("job", "amd.com/gpu"), cpx, cpu, done, ds, static})
+	assert.Len(t, evict, 2)
+	assert.Equal(t, "job", evict[0].Name)
+	assert.Equal(t, "cpx", evict[1].Name)
+}
+
+func TestDCMTaint(t *testing.T) {
+	assert.False(t, hasDCMTaint(nil))
+	assert.False(t, hasDCMTaint([]v1.Taint{{Key: globals.DCMTaintKey, Value: globals.DCMTaintValue, Effect: v1.TaintEffectNoSchedule}}))
+	assert.True(t, hasDCMTaint([]v1.Taint{{Key: "other", Effect: v1.TaintEffectNoExecute}, {Key: globals.DCMTaintKey, Value: globals.DCMTaintValue, Effect: v1.TaintEffectNoExecute}}))
+
+	t.Setenv(globals.TaintPolicyEnv, "Require")
+	assert.Equal(t, globals.TaintPolicyRequire, taintPolicyFromEnv())
+	t.Setenv(globals.TaintPolicyEnv, "bogus")
+	assert.Equal(t, globals.TaintPolicyNone, taintPolicyFromEnv())
+}
+
+func TestGPUWorkloads(t *testing.T) {
+	controller := true
+	pods := []v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "train-0", Namespace: "ml", OwnerReferences: []metav1.OwnerReference{{Kind: "Job", Name: "train", Controller: &controller}}},
+			Spec: v1.PodSpec{Containers: []v1.Container{
+				{Name: "sidecar", Resources: v1.ResourceRequirements{Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")}}},
+				{Name: "main", Resources: v1.ResourceRequirements{Limits: v1.ResourceList{"amd.com/gpu": resource.MustParse("8")}}},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+			Spec:       v1.PodSpec{Containers: []v1.Container{{Name: "web"}}},
+		},
+	}
+	workloads := gpuWorkloads(pods)
+	assert.Equal(t, []types.GPUWorkload{{
+		Namespace:  "ml",
+		Pod:        "train-0",
+		Owner:      "Job/train",
+		Containers: []types.GPUContainer{{Name: "main", Resources: map[string]string{"amd.com/gpu": "8"}}},
+	}}, workloads)
+	assert.Equal(t, "ml/train-0 (Job/train) containers: main[amd.com/gpu=8]", formatGPUWorkloads(workloads))
+	assert.Equal(t, "none", formatGPUWorkloads(nil))
+}
+
+func TestGPUProcesses(t *testing.T) {
+	root := t.TempDir()
+	holder := filepath.Join(root, "4242")
+	assert.NoError(t, os.MkdirAll(filepath.Join(holder, "fd"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(holder, "cmdline"), []byte("python\x00train.py\x00"), 0644))
+	assert.NoError(t, os.Symlink("/dev/kfd", filepath.Join(holder, "fd", "3")))
+	// a host process amdsmi reports without a readable proc entry
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 2, Processes: map[int][]backend.GPUProcess{
+		0: {{PID: 4242, Name: "python"}, {PID: 31, Name: "rccl-test"}},
+		1: {{PID: 4242, Name: "python"}},
+	}})
+	assert.NoError(t, sim.Init())
+
+	processes := gpuProcesses(sim, 2, root)
+	assert.Equal(t, []types.GPUProcess{
+		{PID: 31, Command: "rccl-test", GPUs: []int{0}},
+		{PID: 4242, Command: "python train.py", GPUs: []int{0, 1}, Devices: []string{"/dev/kfd"}},
+	}, processes)
+	assert.Equal(t, "31 rccl-test GPUs [0]; 4242 python train.py GPUs [0 1] /dev/kfd", formatGPUProcesses(processes))
+	assert.Equal(t, "none", formatGPUProcesses(nil))
+}
+
+func TestDevicePluginResources(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4})
+	assert.NoError(t, sim.Init())
+	plan, err := buildPartitionPlan(sim, "test", newTestProfile(), 4)
+	assert.NoError(t, err)
+	// CPX, CPX and DPX for the profile, GPU 3 stays in SPX
+	assert.Equal(t, 19, expectedGPUResources(sim, plan, 4))
+	// CPX splits a MI300A into 6 partitions
+	mi300a := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, MarketName: "AMD Instinct MI300A", SupportedComputePartitions: []string{"SPX", "TPX", "CPX"}})
+	assert.NoError(t, mi300a.Init())
+	assert.Equal(t, 9, expectedGPUResources(mi300a, types.PartitionPlan{GPUs: []types.GPUPlan{{GpuID: 0, TargetCompute: "CPX"}}}, 4))
+
+	allocatable := v1.ResourceList{
+		v1.ResourceCPU:     resource.MustParse("64"),
+		"amd.com/cpx_nps1": resource.MustParse("16"),
+		"amd.com/dpx_nps1": resource.MustParse("2"),
+		"amd.com/gpu":      resource.MustParse("1"),
+	}
+	assert.Equal(t, int64(19), gpuResourceCount(allocatable))
+
+	controller := true
+	daemonSet := []metav1.OwnerReference{{Kind: "DaemonSet", Name: "amdgpu-device-plugin-daemonset", Controller: &controller}}
+	pods := []v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "amdgpu-device-plugin-daemonset-x7k2p", Namespace: "kube-system", OwnerReferences: daemonSet}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "device-plugin-debug", Namespace: "default"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "device-config-manager-4hq9z", Namespace: "kube-amd-gpu", OwnerReferences: daemonSet}},
+	}
+	plugins := devicePluginPods(pods, globals.DefaultDevicePluginPod)
+	assert.Len(t, plugins, 1)
+	assert.Equal(t, "amdgpu-device-plugin-daemonset-x7k2p", plugins[0].Name)
+
+	t.Setenv(globals.DevicePluginPolicyEnv, "Restart")
+	assert.Equal(t, globals.DevicePluginPolicyRestart, devicePluginPolicyFromEnv())
+	t.Setenv(globals.DevicePluginPolicyEnv, "bogus")
+	assert.Equal(t, globals.DevicePluginPolicyNone, devicePluginPolicyFromEnv())
+	t.Setenv(globals.AllocatableTimeoutEnv, "-1s")
+	assert.Equal(t, globals.DefaultAllocatableTimeout, allocatableTimeoutFromEnv())
+}
+
+func TestVerifyPartitions(t *testing.T) {
+	sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4})
+	assert.NoError(t, sim.Init())
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.False(t, partition_failed)
+	plan := types.PartitionPlan{GPUs: []types.GPUPlan{
+		{GpuID: 0, TargetCompute: "CPX"},
+		{GpuID: 1, TargetCompute: "CPX"},
+		{GpuID: 2, TargetCompute: "DPX"},
+	}}
+
+	// GPU 0 has 8 KFD nodes but only 6 render nodes, GPU 2 lost a partition
+	root := t.TempDir()
+	drm := filepath.Join(root, "class", "drm")
+	nodes := filepath.Join(root, "class", "kfd", "kfd", "topology", "nodes")
+	node, minor := 0, 128
+	for gpuID, partitions := range map[int]int{0: 8, 1: 8, 2: 1} {
+		for i := range partitions {
+			dir := filepath.Join(nodes, fmt.Sprint(node))
+			assert.NoError(t, os.MkdirAll(dir, 0755))
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "gpu_id"), []byte(fmt.Sprint(1000+node)), 0644))
+			properties := fmt.Sprintf("drm_render_minor %d\nlocation_id %d\ndomain 0\n", minor, i<<28|(gpuID+1)<<8)
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, "properties"), []byte(properties), 0644))
+			if gpuID != 0 || i < 6 {
+				assert.NoError(t, os.MkdirAll(filepath.Join(drm, fmt.Sprintf("renderD%d", minor)), 0755))
+			}
+			node++
+			minor++
+		}
+	}
+
+	mismatches := verifyPartitions(sim, plan, root)
+	assert.Equal(t, []string{
+		"CPX implies 8 partitions, found 6 render nodes",
+		"",
+		"DPX implies 2 partitions, found 1 KFD nodes, 1 render nodes",
+	}, mismatches)
+
+	// without a KFD only the amdsmi processor handles are checked
+	assert.Equal(t, []string{"", "", ""}, verifyPartitions(sim, plan, t.TempDir()))
+
+	t.Setenv(backend.SysfsRootEnv, root)
+	amdSMIHelper(context.Background(), sim, "test", newTestProfile())
+	assert.True(t, partition_failed)
+	assert.Equal(t, "Failure", partStatus.GPUStatus[0].Status)
+	assert.Equal(t, "Success", partStatus.GPUStatus[1].Status)
+	assert.Contains(t, partStatus.Reason, "Partition verification failed")
+	// CPX splits a MI300A into 6 and a MI308X into 4 partitions
+	for model, partitions := range map[string]int{"MI300A": 6, "MI308X": 4} {
+		sim := backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, MarketName: "AMD Instinct " + model,
+			SupportedComputePartitions: []string{"SPX", "CPX"}, SupportedMemoryPartitions: []string{"NPS1"}})
+		assert.NoError(t, sim.Init())
+		cpx := types.PartitionPlan{GPUs: []types.GPUPlan{{GpuID: 0, TargetCompute: "CPX"}}}
+		assert.NoError(t, sim.SetComputePartition(0, "CPX"))
+		assert.Equal(t, []string{""}, verifyPartitions(sim, cpx, t.TempDir()), model)
+		count, ok := gpuPartitionCount(sim, 0, "CPX")
+		assert.True(t, ok)
+		assert.Equal(t, partitions, count, model)
+	}
+
+	// the verification is skipped for an unknown model
+	sim = backend.NewSimBackend(backend.SimConfig{NumGPUs: 4, MarketName: "AMD Instinct MI999", DeviceID: 0xffff})
+	assert.NoError(t, sim.Init())
+	_, ok := gpuPartitionCount(sim, 0, "CPX")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"", "", ""}, verifyPartitions(sim, plan, root))
+}
+
+func TestApplyProfileJournal(t *testing.T) {
+	_, dir := newStandaloneTest(t, backend.SimConfig{NumGPUs: 4})
+	journalFile := filepath.Join(dir, "journal.json")
+	t.Setenv(globals.JournalFileEnv, journalFile)
+
+	// the journal of a daemon run is left alone
+	assert.NoError(t, os.WriteFile(journalFile, []byte(`{"RunID": "daemon"}`), 0644))
+	_, err := ApplyProfile("test")
+	assert.ErrorContains(t, err, "partition journal")
+	assert.FileExists(t, journalFile)
+
+	assert.NoError(t, os.Remove(journalFile))
+	// a daemon run holds the run lock
+	lock, err := lockRun()
+	assert.NoError(t, err)
+	_, err = ApplyProfile("test")
+	assert.ErrorIs(t, err, errRunLocked)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = waitRunLock(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NoError(t, lock.Close())
+
+	status, err := ApplyProfile("test")
+	assert.NoError(t, err)
+	assert.Equal(t, "Success", status.FinalStatus)
+	assert.NoFileExists(t, journalFile)
+}
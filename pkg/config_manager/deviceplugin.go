@@ -0,0 +1,175 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// interval between checks of the allocatable resources of the node
+const allocatablePollInterval = 5 * time.Second
+
+// devicePluginPolicyFromEnv returns the device plugin policy, the GPU
+// resources of the node are not checked by default
+func devicePluginPolicyFromEnv() string {
+	return envChoice(globals.DevicePluginPolicyEnv, globals.DevicePluginPolicyNone, globals.DevicePluginPolicyNone, globals.DevicePluginPolicyWait, globals.DevicePluginPolicyRestart)
+}
+
+// devicePluginPodFromEnv returns the string identifying the device plugin
+// pods by name
+func devicePluginPodFromEnv() string {
+	if pod := os.Getenv(globals.DevicePluginPodEnv); pod != "" {
+		return pod
+	}
+	return globals.DefaultDevicePluginPod
+}
+
+// allocatableTimeoutFromEnv returns how long to wait for the node to
+// advertise the GPU resources of the profile
+func allocatableTimeoutFromEnv() time.Duration {
+	timeout := envDuration(globals.AllocatableTimeoutEnv, globals.DefaultAllocatableTimeout)
+	if timeout == 0 {
+		log_e.Errorf("Invalid %v %v, using %v", globals.AllocatableTimeoutEnv, timeout, globals.DefaultAllocatableTimeout)
+		return globals.DefaultAllocatableTimeout
+	}
+	return timeout
+}
+
+// expectedGPUResources returns the number of GPU partitions the node has
+// once the plan is applied, GPUs left out of the plan keep their current
+// partitions
+func expectedGPUResources(gpu backend.GPUBackend, plan types.PartitionPlan, gpuCount int) int {
+	planned := map[int]string{}
+	for _, gpuPlan := range plan.GPUs {
+		planned[gpuPlan.GpuID] = gpuPlan.TargetCompute
+	}
+	expected := 0
+	for id := range gpuCount {
+		if count, ok := gpuPartitionCount(gpu, id, planned[id]); ok {
+			expected += count
+			continue
+		}
+		count, err := gpu.GetProcessorCount(id)
+		if err != nil {
+			log_e.Errorf("GPU ID %v: failed to get the partition count: %v", id, err)
+			continue
+		}
+		expected += count
+	}
+	return expected
+}
+
+// gpuResourceCount sums the allocatable AMD GPU resources of a node, e.g.
+// amd.com/gpu, or amd.com/cpx_nps1 with the mixed resource strategy
+func gpuResourceCount(allocatable v1.ResourceList) int64 {
+	var count int64
+	for name, quantity := range allocatable {
+		if strings.HasPrefix(string(name), globals.GPUResourcePrefix) {
+			count += quantity.Value()
+		}
+	}
+	return count
+}
+
+// devicePluginPods returns the DaemonSet pods of the node whose name
+// contains pattern
+func devicePluginPods(pods []v1.Pod, pattern string) []v1.Pod {
+	plugins := []v1.Pod{}
+	for _, pod := range pods {
+		owner := metav1.GetControllerOf(&pod)
+		if owner == nil || owner.Kind != "DaemonSet" || !strings.Contains(pod.Name, pattern) {
+			continue
+		}
+		plugins = append(plugins, pod)
+	}
+	return plugins
+}
+
+// restartDevicePlugin deletes the device plugin pods of the node, their
+// DaemonSet recreates them and they advertise the new partitions
+func restartDevicePlugin() error {
+	pods, err := kc.GetNodePods(nodeName)
+	if err != nil {
+		return fmt.Errorf("failed to list the pods of node %v: %v", nodeName, err)
+	}
+	plugins := devicePluginPods(pods, devicePluginPodFromEnv())
+	if len(plugins) == 0 {
+		log.Printf("No device plugin pod matching %q on node %v", devicePluginPodFromEnv(), nodeName)
+		return nil
+	}
+	for _, pod := range plugins {
+		if err := kc.DeletePod(pod.Namespace, pod.Name); err != nil {
+			return fmt.Errorf("failed to restart device plugin pod %v/%v: %v", pod.Namespace, pod.Name, err)
+		}
+		log.Printf("Restarted device plugin pod %v/%v", pod.Namespace, pod.Name)
+	}
+	return nil
+}
+
+// verifyGPUResources coordinates with the device plugin once the GPUs are
+// partitioned and waits until the node advertises the GPU resources the plan
+// implies or ctx is cancelled. The device plugin is only restarted when
+// partitions changed.
+func verifyGPUResources(ctx context.Context, gpu backend.GPUBackend, plan types.PartitionPlan, partitionChanged bool) error {
+	policy := devicePluginPolicyFromEnv()
+	if policy == globals.DevicePluginPolicyNone || nodeName == "" || IsStandaloneMode() {
+		return nil
+	}
+	if policy == globals.DevicePluginPolicyRestart && partitionChanged {
+		if err := restartDevicePlugin(); err != nil {
+			return err
+		}
+	}
+
+	expected := int64(expectedGPUResources(gpu, plan, totalGPUCount))
+	timeout := allocatableTimeoutFromEnv()
+	deadline := time.Now().Add(timeout)
+	log.Printf("Waiting for node %v to advertise %d GPU resources", nodeName, expected)
+	var advertised int64
+	for {
+		allocatable, err := kc.GetNodeAllocatable(nodeName)
+		if err != nil {
+			log_e.Errorf("Failed to get the allocatable resources of node %v: %v", nodeName, err)
+		} else {
+			advertised = gpuResourceCount(allocatable)
+			if advertised == expected {
+				log.Printf("Node %v advertises %d GPU resources", nodeName, advertised)
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("node %v advertises %d GPU resources after %v, the profile implies %d", nodeName, advertised, timeout, expected)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(allocatablePollInterval):
+		}
+	}
+}
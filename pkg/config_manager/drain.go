@@ -0,0 +1,142 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	log_e "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// interval between evictions and checks of the pods left on the node
+const drainPollInterval = 5 * time.Second
+
+// drainPolicyFromEnv returns the drain policy, the node is not drained by
+// default
+func drainPolicyFromEnv() string {
+	return envChoice(globals.DrainPolicyEnv, globals.DrainPolicyNone, globals.DrainPolicyNone, globals.DrainPolicyDrain)
+}
+
+// drainTimeoutFromEnv returns how long to wait for the pods using GPUs to
+// leave the node
+func drainTimeoutFromEnv() time.Duration {
+	timeout := envDuration(globals.DrainTimeoutEnv, globals.DefaultDrainTimeout)
+	if timeout == 0 {
+		log_e.Errorf("Invalid %v %v, using %v", globals.DrainTimeoutEnv, timeout, globals.DefaultDrainTimeout)
+		return globals.DefaultDrainTimeout
+	}
+	return timeout
+}
+
+// gpuPodsToEvict returns the pods of the node that keep GPUs busy, finished
+// pods are skipped along with DaemonSet and static pods which would be
+// recreated on the node right away
+func gpuPodsToEvict(pods []v1.Pod) []v1.Pod {
+	evict := []v1.Pod{}
+	for _, pod := range pods {
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed || !podRequestsGPU(&pod) {
+			continue
+		}
+		if _, mirror := pod.Annotations[v1.MirrorPodAnnotationKey]; mirror {
+			log.Printf("Skipping static pod %v/%v", pod.Namespace, pod.Name)
+			continue
+		}
+		if owner := metav1.GetControllerOf(&pod); owner != nil && owner.Kind == "DaemonSet" {
+			log.Printf("Skipping DaemonSet pod %v/%v", pod.Namespace, pod.Name)
+			continue
+		}
+		evict = append(evict, pod)
+	}
+	return evict
+}
+
+// drainNode cordons the node and evicts the pods using GPUs, then waits for
+// them to leave until the drain timeout expires. cordoned reports whether
+// the node was cordoned here and has to be uncordoned afterwards.
+func drainNode(ctx context.Context) (cordoned bool, err error) {
+	log.Printf("Draining node %v", nodeName)
+	wasUnschedulable, err := kc.SetNodeUnschedulable(nodeName, true)
+	if err != nil {
+		return false, fmt.Errorf("failed to cordon node %v: %v", nodeName, err)
+	}
+	cordoned = !wasUnschedulable
+	journalCordon(cordoned)
+
+	timeout := drainTimeoutFromEnv()
+	deadline := time.Now().Add(timeout)
+	remaining := []string{}
+	for {
+		pods, err := kc.GetNodePods(nodeName)
+		if err != nil {
+			log_e.Errorf("Failed to list the pods of node %v: %v", nodeName, err)
+		} else {
+			remaining = evictGPUPods(gpuPodsToEvict(pods))
+			if len(remaining) == 0 {
+				log.Printf("No pods using GPUs left on node %v", nodeName)
+				return cordoned, nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return cordoned, fmt.Errorf("pods using GPUs still on node %v after %v: %v", nodeName, timeout, strings.Join(remaining, ", "))
+		}
+		select {
+		case <-ctx.Done():
+			return cordoned, ctx.Err()
+		case <-time.After(drainPollInterval):
+		}
+	}
+}
+
+// evictGPUPods evicts the pods not terminating yet and returns the names of
+// all of them
+func evictGPUPods(pods []v1.Pod) []string {
+	names := []string{}
+	for _, pod := range pods {
+		names = append(names, pod.Namespace+"/"+pod.Name)
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		err := kc.EvictPod(pod.Namespace, pod.Name)
+		switch {
+		case err == nil:
+			log.Printf("Evicted pod %v/%v", pod.Namespace, pod.Name)
+		case apierrors.IsTooManyRequests(err):
+			log.Printf("Eviction of pod %v/%v blocked by a PodDisruptionBudget, retrying", pod.Namespace, pod.Name)
+		case apierrors.IsNotFound(err):
+		default:
+			log_e.Errorf("Failed to evict pod %v/%v: %v", pod.Namespace, pod.Name, err)
+		}
+	}
+	return names
+}
+
+// uncordonNode makes the node schedulable again after a drain
+func uncordonNode() {
+	if _, err := kc.SetNodeUnschedulable(nodeName, false); err != nil {
+		log_e.Errorf("Failed to uncordon node %v: %v", nodeName, err)
+		return
+	}
+	journalCordon(false)
+}
@@ -0,0 +1,55 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	log_e "github.com/sirupsen/logrus"
+)
+
+// envChoice returns the lower cased value of the environment variable when
+// it is one of the allowed values, def when it is unset or invalid
+func envChoice(name, def string, allowed ...string) string {
+	value := strings.ToLower(os.Getenv(name))
+	if value == "" {
+		return def
+	}
+	if !slices.Contains(allowed, value) {
+		log_e.Errorf("Invalid %v %q, using %v", name, value, def)
+		return def
+	}
+	return value
+}
+
+// envDuration returns the duration of the environment variable, def when it
+// is unset or invalid. Negative durations are invalid, zero is returned as is.
+func envDuration(name string, def time.Duration) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		log_e.Errorf("Invalid %v %q, using %v", name, value, def)
+		return def
+	}
+	return d
+}
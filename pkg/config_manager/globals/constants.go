@@ -20,12 +20,26 @@ import "time"
 
 const (
 	// config map json path inside k8
-	JsonFilePath            = "/etc/config-manager/config.json"
-	DefaultComputePartition = "SPX"
-	DefaultMemoryPartition  = "NPS1"
-	DefaultProfileName      = "default"
-	LabelKey                = "dcm.amd.com/gpu-config-profile"
-	TriggerLabelKey         = "dcm.amd.com/apply-gpu-config-profile"
+	JsonFilePath = "/etc/config-manager/config.json"
+	// yaml config, only read when config.json is not present
+	YamlFilePath = "/etc/config-manager/config.yaml"
+	// standalone (non k8s) mode profile selection and status files
+	StandaloneProfileFilePath = "/etc/config-manager/profile"
+	StandaloneStateFilePath   = "/var/lib/amd-device-config-manager/state.json"
+	DefaultComputePartition   = "SPX"
+	DefaultMemoryPartition    = "NPS1"
+	DefaultProfileName        = "default"
+	LabelKey                  = "dcm.amd.com/gpu-config-profile"
+	TriggerLabelKey           = "dcm.amd.com/apply-gpu-config-profile"
+	ProfileStateLabelKey      = "dcm.amd.com/gpu-config-profile-state"
+	// when set to true on the node the partition plan is published instead of applied
+	PlanOnlyAnnotationKey = "dcm.amd.com/plan-only"
+	PlanAnnotationKey     = "dcm.amd.com/gpu-config-plan"
+	// partition modes supported by all GPUs of the node, joined with "_"
+	ComputeCapsLabelKey = "dcm.amd.com/gpu-compute-partitions"
+	MemoryCapsLabelKey  = "dcm.amd.com/gpu-memory-partitions"
+	// per GPU partition modes as json
+	CapabilitiesAnnotationKey = "dcm.amd.com/gpu-partition-capabilities"
 
 	EventSourceComponentName       = "amd-device-config-manager"
 	K8EventInvalidComputeType      = "InvalidComputeType"
@@ -40,11 +54,117 @@ const (
 	K8EventConfigMapNotPresent     = "ConfigMapNotPresent"
 	K8EventInvalidJSONInConfigMap  = "InvalidJSONInConfigMap"
 	K8EventAMDSMIAPIFailure        = "AMDSMIAPIFailure"
+	K8EventPartitionPlan           = "PartitionPlan"
+	K8EventPartitionPlanFailed     = "PartitionPlanFailure"
+	// raised when any profile of the configmap is invalid, not only the selected one
+	K8EventInvalidProfilesInConfigMap = "InvalidProfilesInConfigMap"
+	// raised when the GPUs no longer match the applied profile
+	K8EventPartitionDrifted = "PartitionDrifted"
+	// raised at startup when the journal shows a partition run was cut short
+	K8EventPartitionInterrupted = "PartitionInterrupted"
+	// raised when the node was drained for partitioning busy GPUs
+	K8EventNodeDrained      = "NodeDrained"
+	K8EventNodeDrainFailure = "NodeDrainFailure"
+	// raised when the node does not advertise the GPU resources the profile
+	// implies after partitioning
+	K8EventGPUResourcesNotConverged = "GPUResourcesNotConverged"
+	// raised when a partitioned GPU exposes fewer or more partitions than
+	// its compute type implies
+	K8EventPartitionVerificationFailed = "PartitionVerificationFailure"
 )
 
-var ValidComputePartitions = []string{"SPX", "CPX", "DPX", "QPX"}
+const (
+	// environment variables of the drift reconciler, an interval of 0
+	// disables it
+	ReconcileIntervalEnv = "DCM_RECONCILE_INTERVAL"
+	DriftPolicyEnv       = "DCM_DRIFT_POLICY"
+	// drift policies, report only flags the node while reapply partitions
+	// the GPUs again
+	DriftPolicyReport        = "report"
+	DriftPolicyReapply       = "reapply"
+	DefaultReconcileInterval = 5 * time.Minute
+)
+
+const (
+	// environment variable setting the port of the metrics and probe
+	// endpoints, 0 disables them
+	HTTPPortEnv     = "DCM_HTTP_PORT"
+	DefaultHTTPPort = 9500
+)
+
+const (
+	// environment variable setting the unix socket of the gRPC service, an
+	// empty value disables the service
+	GRPCSocketEnv = "DCM_GRPC_SOCKET"
+	// the directory is shared with the host so node local agents can connect
+	DefaultGRPCSocketPath = "/var/lib/amd-device-config-manager/dcm.sock"
+)
+
+const (
+	// environment variables of the drain done when the GPUs are busy, the
+	// node is cordoned and the pods using GPUs are evicted before retrying
+	DrainPolicyEnv  = "DCM_DRAIN_POLICY"
+	DrainTimeoutEnv = "DCM_DRAIN_TIMEOUT"
+	// drain policies, the node is left as is by default
+	DrainPolicyNone     = "none"
+	DrainPolicyDrain    = "drain"
+	DefaultDrainTimeout = 10 * time.Minute
+	// prefix of the extended resources of AMD GPUs, e.g. amd.com/gpu
+	GPUResourcePrefix = "amd.com/"
+)
+
+const (
+	// environment variable of the amd-dcm taint handling around partitioning
+	TaintPolicyEnv = "DCM_TAINT_POLICY"
+	// taint policies, none leaves the taint to the user, manage taints the
+	// node while partitioning and require refuses to partition an untainted
+	// node
+	TaintPolicyNone    = "none"
+	TaintPolicyManage  = "manage"
+	TaintPolicyRequire = "require"
+	// taint tolerated by the DCM daemonset, pods without the toleration are
+	// evicted while the GPUs are partitioned
+	DCMTaintKey   = "amd-dcm"
+	DCMTaintValue = "up"
+)
+
+const (
+	// environment variables of the device plugin coordination after
+	// partitioning, the node has to advertise the GPU resources the profile
+	// implies before the profile state is set to success
+	DevicePluginPolicyEnv = "DCM_DEVICE_PLUGIN_POLICY"
+	DevicePluginPodEnv    = "DCM_DEVICE_PLUGIN_POD"
+	AllocatableTimeoutEnv = "DCM_ALLOCATABLE_TIMEOUT"
+	// device plugin policies, none skips the check, wait only waits for the
+	// allocatable resources and restart deletes the device plugin pods of the
+	// node first so they enumerate the new partitions
+	DevicePluginPolicyNone    = "none"
+	DevicePluginPolicyWait    = "wait"
+	DevicePluginPolicyRestart = "restart"
+	// device plugin pods are the DaemonSet pods of the node whose name
+	// contains this string by default
+	DefaultDevicePluginPod    = "device-plugin"
+	DefaultAllocatableTimeout = 5 * time.Minute
+)
+
+const (
+	// environment variable overriding the partition journal, the write-ahead
+	// record used to recover from a crash in the middle of a partition run
+	JournalFileEnv         = "DCM_JOURNAL_FILE"
+	DefaultJournalFilePath = "/var/lib/amd-device-config-manager/journal.json"
+)
+
+var ValidComputePartitions = []string{"SPX", "CPX", "DPX", "QPX", "TPX"}
 var ValidMemoryPartitions = []string{"NPS1", "NPS2", "NPS4"}
 
+// PartitionCompatibility lists the memory partitions each compute partition
+// can be combined with, per GPU model
+var PartitionCompatibility = map[string]map[string][]string{
+	"MI300X": {"SPX": {"NPS1"}, "DPX": {"NPS1", "NPS2"}, "QPX": {"NPS1"}, "CPX": {"NPS1", "NPS4"}},
+	"MI325X": {"SPX": {"NPS1"}, "DPX": {"NPS1", "NPS2"}, "QPX": {"NPS1"}, "CPX": {"NPS1", "NPS4"}},
+	"MI300A": {"SPX": {"NPS1"}, "TPX": {"NPS1"}, "CPX": {"NPS1"}},
+}
+
 const (
 	KMMDriverRecoveryUnloadTimeout = 30 * time.Second
 	KMMDriverRecoveryTimeout       = 5 * time.Minute
@@ -0,0 +1,275 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// events buffered per WatchEvents stream, events are dropped for streams
+// that fall further behind
+const watchEventBuffer = 64
+
+var (
+	statusMu   sync.Mutex
+	lastStatus types.PartitionStatus
+
+	watchersMu sync.Mutex
+	watchers   = map[chan *partition_pb.PartitionEvent]struct{}{}
+)
+
+// recordPartitionStatus keeps a copy of the partition status for GetStatus
+func recordPartitionStatus(partStatus types.PartitionStatus) {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	lastStatus = partStatus
+	lastStatus.GPUStatus = append([]types.GPUPartitionStatus(nil), partStatus.GPUStatus...)
+}
+
+func currentPartitionStatus() types.PartitionStatus {
+	statusMu.Lock()
+	defer statusMu.Unlock()
+	return lastStatus
+}
+
+func subscribeEvents() chan *partition_pb.PartitionEvent {
+	events := make(chan *partition_pb.PartitionEvent, watchEventBuffer)
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	watchers[events] = struct{}{}
+	return events
+}
+
+func unsubscribeEvents(events chan *partition_pb.PartitionEvent) {
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	delete(watchers, events)
+}
+
+// publishEvent hands the event to every WatchEvents stream without blocking
+// the partitioning
+func publishEvent(event *partition_pb.PartitionEvent) {
+	event.Time = timestamppb.Now()
+	watchersMu.Lock()
+	defer watchersMu.Unlock()
+	for events := range watchers {
+		select {
+		case events <- event:
+		default:
+			log_e.Warnf("WatchEvents stream is not keeping up, dropping event %v", event)
+		}
+	}
+}
+
+// publishGPUStatus reports the progress of a GPU of the running partitioning
+func publishGPUStatus(partStatus types.PartitionStatus, gpuStatus types.GPUPartitionStatus) {
+	recordPartitionStatus(partStatus)
+	publishEvent(&partition_pb.PartitionEvent{
+		SelectedProfile: partStatus.SelectedProfile,
+		GPU:             toGPUPartitionStatusPB(gpuStatus),
+	})
+}
+
+// publishNodeEvent reports an event raised for the node with its status
+func publishNodeEvent(event_n string, partStatus types.PartitionStatus) {
+	recordPartitionStatus(partStatus)
+	publishEvent(&partition_pb.PartitionEvent{
+		SelectedProfile: partStatus.SelectedProfile,
+		Event:           event_n,
+		Reason:          partStatus.Reason,
+	})
+}
+
+func toGPUPartitionStatusPB(gpuStatus types.GPUPartitionStatus) *partition_pb.GPUPartitionStatus {
+	return &partition_pb.GPUPartitionStatus{
+		GpuID:         uint32(gpuStatus.GpuID),
+		PartitionType: gpuStatus.PartitionType,
+		Status:        gpuStatus.Status,
+		Message:       gpuStatus.Message,
+	}
+}
+
+func toGPUPlanPB(gpuPlan types.GPUPlan) *partition_pb.GPUPlan {
+	return &partition_pb.GPUPlan{
+		GpuID:              uint32(gpuPlan.GpuID),
+		CurrentCompute:     gpuPlan.CurrentCompute,
+		CurrentMemory:      gpuPlan.CurrentMemory,
+		TargetCompute:      gpuPlan.TargetCompute,
+		TargetMemory:       gpuPlan.TargetMemory,
+		ComputeChange:      gpuPlan.ComputeChange,
+		MemoryChange:       gpuPlan.MemoryChange,
+		DriverReloadNeeded: gpuPlan.DriverReloadNeeded,
+		SettingsChange:     gpuPlan.SettingsChange(),
+	}
+}
+
+// dcmServer implements the DeviceConfigManager gRPC service
+type dcmServer struct {
+	partition_pb.UnimplementedDeviceConfigManagerServer
+}
+
+func (s *dcmServer) GetStatus(ctx context.Context, req *partition_pb.GetStatusRequest) (*partition_pb.PartitionStatus, error) {
+	partStatus := currentPartitionStatus()
+	resp := &partition_pb.PartitionStatus{
+		SelectedProfile: partStatus.SelectedProfile,
+		FinalStatus:     partStatus.FinalStatus,
+		Reason:          partStatus.Reason,
+		ProfileState:    currentProfileState(),
+	}
+	for _, gpuStatus := range partStatus.GPUStatus {
+		resp.GPUStatus = append(resp.GPUStatus, toGPUPartitionStatusPB(gpuStatus))
+	}
+	return resp, nil
+}
+
+func (s *dcmServer) ListProfiles(ctx context.Context, req *partition_pb.ListProfilesRequest) (*partition_pb.ListProfilesResponse, error) {
+	cfg, err := LoadConfig(ConfigFilePath())
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return &partition_pb.ListProfilesResponse{Names: ProfileNames(cfg.Profiles), Config: cfg.Profiles}, nil
+}
+
+// Apply plans the profile and, unless it is a dry run, partitions the GPUs
+// through the retry loop the same way a profile label change does
+func (s *dcmServer) Apply(ctx context.Context, req *partition_pb.ApplyRequest) (*partition_pb.ApplyResponse, error) {
+	if req.Profile == "" {
+		return nil, status.Error(codes.InvalidArgument, "profile is required")
+	}
+	cfg, err := LoadConfig(ConfigFilePath())
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if _, exists := cfg.Profiles.ProfilesList[req.Profile]; !exists {
+		return nil, status.Errorf(codes.NotFound, "profile %v does not exist", req.Profile)
+	}
+	// planning would wait for the run to release the GPU backend
+	if backendBusy() {
+		return nil, status.Error(codes.Unavailable, "a partition run is in progress")
+	}
+	plan, err := PlanProfile(req.Profile)
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	resp := &partition_pb.ApplyResponse{ServicesToStop: plan.ServicesToStop}
+	for _, gpuPlan := range plan.GPUs {
+		resp.Plan = append(resp.Plan, toGPUPlanPB(gpuPlan))
+	}
+	if req.DryRun {
+		return resp, nil
+	}
+	// the profile is selected first, otherwise the drift reconciler would
+	// revert it to the previous selection
+	setAppliedSelection(req.Profile)
+	changed, err := selectPartitionProfile(req.Profile)
+	if err != nil || !changed {
+		setAppliedSelection("")
+	}
+	if err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	resp.Started = TriggerRetryLoop(req.Profile, "gRPC Apply")
+	if !resp.Started {
+		setAppliedSelection("")
+		return nil, status.Error(codes.Unavailable, "another partitioning request is pending")
+	}
+	return resp, nil
+}
+
+func (s *dcmServer) WatchEvents(req *partition_pb.WatchEventsRequest, stream grpc.ServerStreamingServer[partition_pb.PartitionEvent]) error {
+	events := subscribeEvents()
+	defer unsubscribeEvents(events)
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func newGRPCServer() *grpc.Server {
+	server := grpc.NewServer()
+	partition_pb.RegisterDeviceConfigManagerServer(server, &dcmServer{})
+	return server
+}
+
+// grpcSocketFromEnv returns the socket the gRPC service listens on, empty
+// when it is disabled
+func grpcSocketFromEnv() string {
+	if path, ok := os.LookupEnv(globals.GRPCSocketEnv); ok {
+		return path
+	}
+	return globals.DefaultGRPCSocketPath
+}
+
+// listenUnix creates the socket, a socket left over by a previous run is
+// replaced
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0660); err != nil {
+		lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
+
+// StartGRPCServer serves the DeviceConfigManager service on a unix socket of
+// the node
+func StartGRPCServer() {
+	path := grpcSocketFromEnv()
+	if path == "" {
+		log.Printf("gRPC service disabled")
+		return
+	}
+	lis, err := listenUnix(path)
+	if err != nil {
+		log_e.Errorf("Failed to listen on %v: %v", path, err)
+		return
+	}
+	log.Printf("Serving gRPC on %v", path)
+	if err := newGRPCServer().Serve(lis); err != nil {
+		log_e.Errorf("gRPC service stopped: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// components reported by /healthz
+const (
+	componentConfigWatcher      = "config-file-watcher"
+	componentProfileFileWatcher = "profile-file-watcher"
+	componentNodeLabelWatcher   = "node-label-watcher"
+	componentK8sClient          = "k8s-client"
+)
+
+var errWatcherStopped = errors.New("watcher stopped")
+
+var (
+	healthMu sync.Mutex
+	// error of each component, nil when healthy
+	componentHealth = map[string]error{}
+	ready           bool
+)
+
+// setComponentHealth records the health of a component, err is nil when it
+// is healthy
+func setComponentHealth(name string, err error) {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	componentHealth[name] = err
+}
+
+// MarkReady reports the pod ready, called once the initial reconcile has
+// finished or when there is no profile to apply
+func MarkReady() {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	ready = true
+}
+
+func isReady() bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	return ready
+}
+
+// unhealthyComponents returns the error of each unhealthy component
+func unhealthyComponents() map[string]string {
+	if nodeName != "" && !IsStandaloneMode() {
+		setComponentHealth(componentK8sClient, kc.Ready())
+	}
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	unhealthy := map[string]string{}
+	for name, err := range componentHealth {
+		if err != nil {
+			unhealthy[name] = err.Error()
+		}
+	}
+	return unhealthy
+}
+
+// healthzHandler fails while a watcher goroutine has stopped or the k8s
+// API server cannot be reached
+func healthzHandler(w http.ResponseWriter, r *http.Request) {
+	unhealthy := unhealthyComponents()
+	if len(unhealthy) == 0 {
+		w.Write([]byte("ok"))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(unhealthy)
+}
+
+// readyzHandler fails until the initial reconcile has finished
+func readyzHandler(w http.ResponseWriter, r *http.Request) {
+	if !isReady() {
+		http.Error(w, "initial reconcile in progress", http.StatusServiceUnavailable)
+		return
+	}
+	w.Write([]byte("ok"))
+}
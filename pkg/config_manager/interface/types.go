@@ -1,10 +1,47 @@
 package types
 
+import "time"
+
 type PartitionStatus struct {
 	SelectedProfile string
 	FinalStatus     string
 	Reason          string
 	GPUStatus       []GPUPartitionStatus
+	// pods of the node using GPUs, set when partitioning failed with busy
+	GPUWorkloads []GPUWorkload `json:",omitempty"`
+	// processes of the node holding GPUs, set when partitioning failed with
+	// busy
+	GPUProcesses []GPUProcess `json:",omitempty"`
+}
+
+// GPUProcess is a process of the node holding GPUs, found through amdsmi or
+// the device files it holds open
+type GPUProcess struct {
+	PID     int    `json:"pid"`
+	Command string `json:"command,omitempty"`
+	Cgroup  string `json:"cgroup,omitempty"`
+	// empty when the process does not run in a container
+	ContainerID string `json:"containerId,omitempty"`
+	// GPUs amdsmi reports the process on
+	GPUs []int `json:"gpus,omitempty"`
+	// device files held open, e.g. /dev/kfd
+	Devices []string `json:"devices,omitempty"`
+}
+
+// GPUWorkload is a pod of the node requesting AMD GPU resources
+type GPUWorkload struct {
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	// controller of the pod as kind/name, e.g. Job/train, empty for bare pods
+	Owner      string         `json:"owner,omitempty"`
+	Containers []GPUContainer `json:"containers"`
+}
+
+// GPUContainer is a container and the AMD GPU resources it requests
+type GPUContainer struct {
+	Name string `json:"name"`
+	// quantities by resource name, e.g. amd.com/gpu
+	Resources map[string]string `json:"resources"`
 }
 
 type GPUPartitionStatus struct {
@@ -12,4 +49,164 @@ type GPUPartitionStatus struct {
 	PartitionType string
 	Status        string
 	Message       string
+	// set when the partition config references an accelerator profile
+	AcceleratorProfile *AcceleratorProfileStatus `json:",omitempty"`
+	// power cap applied by the profile
+	PowerCapWatts uint32 `json:",omitempty"`
+	// performance level and clock limits by clock domain, set when the
+	// profile requests them
+	PerfLevel string                `json:",omitempty"`
+	Clocks    map[string]ClockRange `json:",omitempty"`
+	// power profile preset and process isolation, set when the profile
+	// requests them
+	PowerProfile     string `json:",omitempty"`
+	ProcessIsolation *bool  `json:",omitempty"`
+}
+
+// ClockRange is a clock frequency range in MHz
+type ClockRange struct {
+	MinMHz uint32 `json:"minMHz"`
+	MaxMHz uint32 `json:"maxMHz"`
+}
+
+// AcceleratorProfileStatus is the accelerator partition profile of a GPU with
+// the resources each of its partitions got
+type AcceleratorProfileStatus struct {
+	Index      int
+	Type       string
+	Partitions []PartitionResources
+}
+
+// PartitionResources are the resources of one partition of a GPU
+type PartitionResources struct {
+	PartitionID int
+	// resource counts by type, e.g. XCC
+	Resources   map[string]int
+	MemoryBytes uint64
+}
+
+// NodeState is the status written to the local state file in standalone mode,
+// it carries the information published as node labels and events in k8s
+type NodeState struct {
+	ProfileState    string
+	LastEvent       string
+	LastUpdated     time.Time
+	PartitionStatus PartitionStatus
+	Plan            *PartitionPlan    `json:",omitempty"`
+	ConfigIssues    []ValidationIssue `json:",omitempty"`
+	Capabilities    []GPUCapabilities `json:",omitempty"`
+}
+
+// GPUPlan describes the partition change a profile would make on one GPU
+type GPUPlan struct {
+	GpuID          int    `json:"gpuId"`
+	PartitionType  string `json:"partitionType,omitempty"`
+	CurrentCompute string `json:"currentCompute"`
+	CurrentMemory  string `json:"currentMemory"`
+	TargetCompute  string `json:"targetCompute,omitempty"`
+	TargetMemory   string `json:"targetMemory,omitempty"`
+	ComputeChange  bool   `json:"computeChange"`
+	MemoryChange   bool   `json:"memoryChange"`
+	// a memory partition change reloads the amdgpu driver
+	DriverReloadNeeded bool `json:"driverReloadNeeded"`
+	// accelerator partition profile indices, set when the partition config
+	// references an accelerator profile
+	CurrentAcceleratorProfile *int `json:"currentAcceleratorProfile,omitempty"`
+	TargetAcceleratorProfile  *int `json:"targetAcceleratorProfile,omitempty"`
+	// GPU settings applied after partitioning, only set when requested
+	CurrentPowerCapWatts uint32 `json:"currentPowerCapWatts,omitempty"`
+	TargetPowerCapWatts  uint32 `json:"targetPowerCapWatts,omitempty"`
+	PowerCapChange       bool   `json:"powerCapChange,omitempty"`
+	CurrentPerfLevel     string `json:"currentPerfLevel,omitempty"`
+	TargetPerfLevel      string `json:"targetPerfLevel,omitempty"`
+	// GFX clock soft maximum of the DETERMINISM performance level
+	DeterminismClockMHz uint32      `json:"determinismClockMHz,omitempty"`
+	PerfLevelChange     bool        `json:"perfLevelChange,omitempty"`
+	Clocks              []ClockPlan `json:"clocks,omitempty"`
+	CurrentPowerProfile string      `json:"currentPowerProfile,omitempty"`
+	TargetPowerProfile  string      `json:"targetPowerProfile,omitempty"`
+	PowerProfileChange  bool        `json:"powerProfileChange,omitempty"`
+	// TargetProcessIsolation is nil when the profile leaves it as is
+	CurrentProcessIsolation bool  `json:"currentProcessIsolation,omitempty"`
+	TargetProcessIsolation  *bool `json:"targetProcessIsolation,omitempty"`
+	ProcessIsolationChange  bool  `json:"processIsolationChange,omitempty"`
+}
+
+// ClockPlan is the change of the limits of one clock domain, a 0 bound in
+// Target keeps the current bound
+type ClockPlan struct {
+	Type    string     `json:"type"`
+	Current ClockRange `json:"current"`
+	Target  ClockRange `json:"target"`
+	Change  bool       `json:"change"`
+}
+
+// PartitionPlan lists the changes applying a profile would make on the node
+type PartitionPlan struct {
+	SelectedProfile string    `json:"selectedProfile"`
+	GPUs            []GPUPlan `json:"gpus"`
+	// systemd services stopped while the GPUs are partitioned
+	ServicesToStop []string `json:"servicesToStop"`
+}
+
+// SettingsChange reports whether any GPU setting applied after partitioning
+// changes
+func (g *GPUPlan) SettingsChange() bool {
+	if g.PowerCapChange || g.PerfLevelChange || g.PowerProfileChange || g.ProcessIsolationChange {
+		return true
+	}
+	for _, c := range g.Clocks {
+		if c.Change {
+			return true
+		}
+	}
+	return false
+}
+
+// PartitionNeeded reports whether any GPU of the plan changes mode
+func (p *PartitionPlan) PartitionNeeded() bool {
+	for _, gpu := range p.GPUs {
+		if gpu.ComputeChange || gpu.MemoryChange {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationIssue is a problem found in a GPU config profile, Path points at
+// the offending field, e.g. gpu-config-profiles.default.profiles[1].computePartition
+type ValidationIssue struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// GPUCapabilities lists the partition modes supported by one GPU, empty lists
+// mean the modes could not be discovered
+type GPUCapabilities struct {
+	GpuID int `json:"gpuId"`
+	// model keying globals.PartitionCompatibility, empty when not known
+	Model             string   `json:"model,omitempty"`
+	ComputePartitions []string `json:"computePartitions"`
+	MemoryPartitions  []string `json:"memoryPartitions"`
+	// empty when the GPU has no accelerator partition profiles
+	AcceleratorProfiles []AcceleratorProfile `json:"acceleratorProfiles,omitempty"`
+	// valid power cap range, zero when not known
+	MinPowerCapWatts uint32 `json:"minPowerCapWatts,omitempty"`
+	MaxPowerCapWatts uint32 `json:"maxPowerCapWatts,omitempty"`
+	// clock ranges accepted by clock domain, empty when not known
+	ClockLimits map[string]ClockRange `json:"clockLimits,omitempty"`
+	// power profile presets, empty when not known
+	PowerProfiles []string `json:"powerProfiles,omitempty"`
+}
+
+// AcceleratorProfile is an accelerator partition profile supported by a GPU
+type AcceleratorProfile struct {
+	Index         int    `json:"index"`
+	Type          string `json:"type"`
+	NumPartitions int    `json:"numPartitions"`
+	// memory partitions the profile can be used with
+	MemoryPartitions []string `json:"memoryPartitions"`
+	// resources of each partition by type, e.g. XCC
+	Resources map[string]int `json:"resources,omitempty"`
 }
@@ -0,0 +1,349 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	utils "github.com/ROCm/device-config-manager/pkg/partition/utils"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// partitionJournal is the write-ahead record of a partition run, it is
+// written before the GPU client services are stopped and removed once they
+// are started again
+type partitionJournal struct {
+	RunID     string
+	Profile   string
+	StartedAt time.Time
+	UpdatedAt time.Time
+	// GPU client services of the config and the state each stopped service
+	// had before the run
+	Services  []string
+	PreStates map[string]utils.ServicePreState
+	// progress of the GPUs of the last attempt
+	GPUStatus []types.GPUPartitionStatus `json:",omitempty"`
+	// set while the node is cordoned by a drain of the run
+	Cordoned bool `json:",omitempty"`
+	// set while the node carries the amd-dcm taint added by the run
+	Tainted bool `json:",omitempty"`
+}
+
+var (
+	journalMu sync.Mutex
+	// journal of the running partition run, nil when none is running
+	journal *partitionJournal
+)
+
+func journalFileFromEnv() string {
+	if path := os.Getenv(globals.JournalFileEnv); path != "" {
+		return path
+	}
+	return globals.DefaultJournalFilePath
+}
+
+// writeJournal persists the journal, the file is synced and replaced
+// atomically so a crash leaves either the old or the new record
+func writeJournal(j *partitionJournal) {
+	j.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		log_e.Errorf("failed to marshal partition journal %+v err %+v", j, err)
+		return
+	}
+	path := journalFileFromEnv()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		log_e.Errorf("failed to create journal directory: %v", err)
+		return
+	}
+	tmpFile := path + ".tmp"
+	f, err := os.OpenFile(tmpFile, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		log_e.Errorf("failed to write journal %v: %v", tmpFile, err)
+		return
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log_e.Errorf("failed to write journal %v: %v", tmpFile, err)
+		return
+	}
+	if err := os.Rename(tmpFile, path); err != nil {
+		log_e.Errorf("failed to update journal %v: %v", path, err)
+	}
+}
+
+// errRunLocked is returned while another process holds the run lock
+var errRunLocked = errors.New("another partition run is in flight")
+
+const runLockPollInterval = time.Second
+
+// lockRun takes the exclusive lock next to the journal, it is held by the
+// daemon and by dcmctl apply while they partition so only one of them
+// stops services and writes the journal. Closing the file releases it.
+func lockRun() (*os.File, error) {
+	path := journalFileFromEnv() + ".lock"
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		f.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errRunLocked
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// waitRunLock takes the run lock once the run holding it is done
+func waitRunLock(ctx context.Context) (*os.File, error) {
+	logged := false
+	for {
+		f, err := lockRun()
+		if !errors.Is(err, errRunLocked) {
+			return f, err
+		}
+		if !logged {
+			log.Printf("Waiting for the partition run holding %v.lock", journalFileFromEnv())
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(runLockPollInterval):
+		}
+	}
+}
+
+func readJournal() (*partitionJournal, error) {
+	data, err := os.ReadFile(journalFileFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	var j partitionJournal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return nil, err
+	}
+	return &j, nil
+}
+
+// beginJournal records a partition run before any service is stopped, the
+// pre-states of a superseded run are kept as its services are still stopped
+func beginJournal(selectedProfile string, services []string) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	now := time.Now().UTC()
+	preStates := map[string]utils.ServicePreState{}
+	if journal != nil {
+		preStates = journal.PreStates
+	}
+	journal = &partitionJournal{
+		RunID:     fmt.Sprintf("%x", now.UnixNano()),
+		Profile:   selectedProfile,
+		StartedAt: now,
+		Services:  services,
+		PreStates: preStates,
+	}
+	utils.PreStateHook = journalPreState
+	log.Printf("Partition run %v of profile %v journaled to %v", journal.RunID, selectedProfile, journalFileFromEnv())
+	writeJournal(journal)
+}
+
+// journalPreState records the state of a service before it is stopped
+func journalPreState(preState utils.ServicePreState) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	journal.PreStates[preState.Name] = preState
+	writeJournal(journal)
+}
+
+// journalGPUStatus records the progress of the GPUs of the running attempt
+func journalGPUStatus(gpuStatus []types.GPUPartitionStatus) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	journal.GPUStatus = append([]types.GPUPartitionStatus(nil), gpuStatus...)
+	writeJournal(journal)
+}
+
+// journalCordon records whether the run cordoned the node
+func journalCordon(cordoned bool) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	journal.Cordoned = cordoned
+	writeJournal(journal)
+}
+
+// journalTaint records whether the run tainted the node
+func journalTaint(tainted bool) {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	journal.Tainted = tainted
+	writeJournal(journal)
+}
+
+// finishJournal drops the journal once the services were started again
+func finishJournal() {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return
+	}
+	journal = nil
+	utils.PreStateHook = nil
+	if err := os.Remove(journalFileFromEnv()); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log_e.Errorf("failed to remove journal: %v", err)
+	}
+}
+
+// releaseJournal starts the services stopped by a journaled run and drops
+// the journal, it is used when a run ends before stopping services so the
+// services of a resumed or superseded run are not left stopped
+func releaseJournal() {
+	journalMu.Lock()
+	j := journal
+	journalMu.Unlock()
+	if j == nil {
+		return
+	}
+	startServices(journalServices(j))
+	finishJournal()
+}
+
+// stoppedServices returns the services to start once the running run ends,
+// the given services when no run is journaled
+func stoppedServices(services []string) []string {
+	journalMu.Lock()
+	defer journalMu.Unlock()
+	if journal == nil {
+		return services
+	}
+	return journalServices(journal)
+}
+
+// journalServices returns the services of the run along with the services
+// stopped by the runs it superseded, their pre-states are kept by
+// beginJournal while the list of services is replaced
+func journalServices(j *partitionJournal) []string {
+	services := []string{}
+	seen := map[string]bool{}
+	for _, svc := range j.Services {
+		if !strings.HasSuffix(svc, ".service") {
+			svc += ".service"
+		}
+		if !seen[svc] {
+			seen[svc] = true
+			services = append(services, svc)
+		}
+	}
+	for _, svc := range slices.Sorted(maps.Keys(j.PreStates)) {
+		if !seen[svc] {
+			seen[svc] = true
+			services = append(services, svc)
+		}
+	}
+	return services
+}
+
+// RecoverJournal handles a partition run that was cut short by a crash or an
+// eviction. The run is resumed when its profile is still selected, its
+// stopped services are then started by the resumed run. Otherwise the stopped
+// services are started again right away.
+func RecoverJournal(selectedProfile string) {
+	lock, err := waitRunLock(context.Background())
+	if err != nil {
+		log_e.Errorf("Failed to lock partition runs: %v", err)
+		return
+	}
+	defer lock.Close()
+	j, err := readJournal()
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	if err != nil {
+		log_e.Errorf("Failed to read partition journal %v: %v", journalFileFromEnv(), err)
+		return
+	}
+	log.Printf("Partition run %v of profile %v started at %v was interrupted", j.RunID, j.Profile, j.StartedAt)
+	for _, gpu := range j.GPUStatus {
+		log.Printf("GPU ID %v: %v, %v", gpu.GpuID, gpu.Status, gpu.Message)
+	}
+	if j.PreStates == nil {
+		j.PreStates = map[string]utils.ServicePreState{}
+	}
+	utils.PreStateDB = maps.Clone(j.PreStates)
+	journalMu.Lock()
+	journal = j
+	journalMu.Unlock()
+	// a resumed run drains and taints the node again when needed
+	if j.Cordoned && nodeName != "" {
+		uncordonNode()
+	}
+	if j.Tainted && nodeName != "" {
+		removeNodeTaint()
+	}
+
+	status := types.PartitionStatus{
+		SelectedProfile: j.Profile,
+		FinalStatus:     "Interrupted",
+		GPUStatus:       j.GPUStatus,
+	}
+	if selectedProfile != "" && selectedProfile == j.Profile && !planOnlyRequested() {
+		status.Reason = fmt.Sprintf("Partition run %v was interrupted, resuming profile %v", j.RunID, j.Profile)
+	} else {
+		status.Reason = fmt.Sprintf("Partition run %v was interrupted, profile %v is no longer selected, restarting the stopped services", j.RunID, j.Profile)
+		startServices(journalServices(j))
+		finishJournal()
+	}
+	log.Print(status.Reason)
+	generateK8sEvent(errors.New("partition interrupted"), globals.K8EventPartitionInterrupted, status)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// phases of a partition run timed by dcm_partition_phase_duration_seconds
+const (
+	phaseServiceStop      = "service_stop"
+	phaseMemoryPartition  = "memory_partition"
+	phaseKMMRecoveryWait  = "kmm_recovery_wait"
+	phaseComputePartition = "compute_partition"
+	phaseServiceStart     = "service_start"
+)
+
+var (
+	partitionAttempts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dcm_partition_attempts_total",
+		Help: "Number of partition attempts by profile",
+	}, []string{"profile"})
+	partitionSuccesses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dcm_partition_successes_total",
+		Help: "Number of successful partition attempts by profile",
+	}, []string{"profile"})
+	partitionFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dcm_partition_failures_total",
+		Help: "Number of failed partition attempts by profile and reason, the reason is the event raised for the failure",
+	}, []string{"profile", "reason"})
+	partitionRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dcm_partition_retries_total",
+		Help: "Number of partition retries by profile",
+	}, []string{"profile"})
+	partitionRetryCount = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "dcm_partition_retry_count",
+		Help: "Retries of the running partition retry loop, 0 when no retry loop is running",
+	})
+	partitionRetryExpired = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "dcm_partition_retry_loop_expired_total",
+		Help: "Number of retry loops that gave up after retrying for 30 minutes",
+	}, []string{"profile"})
+	gpuComputePartition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dcm_gpu_compute_partition_info",
+		Help: "Current compute partition of each GPU, always 1",
+	}, []string{"gpu_id", "partition"})
+	gpuMemoryPartition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dcm_gpu_memory_partition_info",
+		Help: "Current memory partition of each GPU, always 1",
+	}, []string{"gpu_id", "partition"})
+	partitionPhaseDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dcm_partition_phase_duration_seconds",
+		Help:    "Time spent in each phase of a partition run",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"phase"})
+
+	metricsRegistry = prometheus.NewRegistry()
+
+	// event of the last failure raised in the running partition attempt
+	failureEventMu        sync.Mutex
+	partitionFailureEvent string
+)
+
+func init() {
+	metricsRegistry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		partitionAttempts,
+		partitionSuccesses,
+		partitionFailures,
+		partitionRetries,
+		partitionRetryCount,
+		partitionRetryExpired,
+		gpuComputePartition,
+		gpuMemoryPartition,
+		partitionPhaseDuration,
+	)
+}
+
+// observePhase records the time spent in a phase started at start
+func observePhase(phase string, start time.Time) {
+	partitionPhaseDuration.WithLabelValues(phase).Observe(time.Since(start).Seconds())
+}
+
+// recordFailureEvent remembers the event of a failure, it becomes the reason
+// of the failed attempt
+func recordFailureEvent(event string) {
+	failureEventMu.Lock()
+	defer failureEventMu.Unlock()
+	partitionFailureEvent = event
+}
+
+// startPartitionAttempt counts a partition attempt of a profile
+func startPartitionAttempt(selectedProfile string) {
+	recordFailureEvent("")
+	partitionAttempts.WithLabelValues(selectedProfile).Inc()
+}
+
+// finishPartitionAttempt counts the outcome of the attempt from its final
+// status, failures without an event are partition call failures
+func finishPartitionAttempt(selectedProfile string) {
+	if partStatus.FinalStatus == "Success" {
+		partitionSuccesses.WithLabelValues(selectedProfile).Inc()
+		return
+	}
+	failureEventMu.Lock()
+	reason := partitionFailureEvent
+	failureEventMu.Unlock()
+	if reason == "" {
+		reason = globals.K8EventPartitionFailed
+	}
+	partitionFailures.WithLabelValues(selectedProfile, reason).Inc()
+}
+
+// recordGPUModes publishes the current partition modes of the GPUs
+func recordGPUModes(gpu backend.GPUBackend, count int) {
+	gpuComputePartition.Reset()
+	gpuMemoryPartition.Reset()
+	for id := 0; id < count; id++ {
+		gpuID := strconv.Itoa(id)
+		if compute, err := gpu.GetComputePartition(id); err == nil {
+			gpuComputePartition.WithLabelValues(gpuID, compute).Set(1)
+		}
+		if memory, err := gpu.GetMemoryPartition(id); err == nil {
+			gpuMemoryPartition.WithLabelValues(gpuID, memory).Set(1)
+		}
+	}
+}
+
+// httpPortFromEnv returns the port of the metrics and probe endpoints, 0
+// disables them
+func httpPortFromEnv() int {
+	value := os.Getenv(globals.HTTPPortEnv)
+	if value == "" {
+		return globals.DefaultHTTPPort
+	}
+	port, err := strconv.Atoi(value)
+	if err != nil || port < 0 || port > 65535 {
+		log_e.Errorf("Invalid %v %q, using %v", globals.HTTPPortEnv, value, globals.DefaultHTTPPort)
+		return globals.DefaultHTTPPort
+	}
+	return port
+}
+
+// StartHTTPServer serves the prometheus metrics on /metrics and the
+// liveness and readiness probes on /healthz and /readyz
+func StartHTTPServer() {
+	port := httpPortFromEnv()
+	if port == 0 {
+		log.Printf("Metrics and probe endpoints disabled")
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("/healthz", healthzHandler)
+	mux.HandleFunc("/readyz", readyzHandler)
+	addr := fmt.Sprintf(":%d", port)
+	log.Printf("Serving metrics and probes on %v", addr)
+	if err := http.ListenAndServe(addr, mux); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log_e.Errorf("HTTP endpoint stopped: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	utils "github.com/ROCm/device-config-manager/pkg/partition/utils"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// path of the config holding the GPU config profiles set through
+// SetConfigFilePath, the configmap mount is searched when empty
+var configFilePath string
+
+var yamlIgnoredWarned bool
+
+// SetConfigFilePath overrides the path the profiles are read from
+func SetConfigFilePath(path string) {
+	configFilePath = path
+}
+
+// ConfigFilePath returns the config file to read, config.json takes
+// precedence over config.yaml when both are present in the configmap mount
+func ConfigFilePath() string {
+	if configFilePath != "" {
+		return configFilePath
+	}
+	if _, err := os.Stat(globals.JsonFilePath); err == nil {
+		if _, err := os.Stat(globals.YamlFilePath); err == nil && !yamlIgnoredWarned {
+			log_e.Warnf("Both %v and %v are present, %v is ignored", globals.JsonFilePath, globals.YamlFilePath, globals.YamlFilePath)
+			yamlIgnoredWarned = true
+		}
+		return globals.JsonFilePath
+	}
+	if _, err := os.Stat(globals.YamlFilePath); err == nil {
+		return globals.YamlFilePath
+	}
+	return globals.JsonFilePath
+}
+
+// normalizeProfile fills in the optional fields of a profile
+func normalizeProfile(profile *partition_pb.GPUConfigProfile) {
+	if profile.Filters == nil {
+		profile.Filters = &partition_pb.SkippedGPUs{}
+		profile.Filters.Id = []uint32{}
+	}
+}
+
+// ProfileNames returns the sorted profile names of a config
+func ProfileNames(profiles *partition_pb.GPUConfigProfiles) []string {
+	names := make([]string, 0, len(profiles.ProfilesList))
+	for name := range profiles.ProfilesList {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// GetGPUCount returns the number of GPUs seen by the GPU backend
+func GetGPUCount() (int, error) {
+	gpu, err := initGPUBackend()
+	if err != nil {
+		return 0, err
+	}
+	defer shutDownAMDSMI(gpu)
+	return gpu.GetGPUCount()
+}
+
+// GetCurrentPartitions returns the compute and memory partition of every GPU
+func GetCurrentPartitions() ([]types.GPUPlan, error) {
+	gpu, err := initGPUBackend()
+	if err != nil {
+		return nil, err
+	}
+	defer shutDownAMDSMI(gpu)
+	count, err := gpu.GetGPUCount()
+	if err != nil {
+		return nil, err
+	}
+	current := make([]types.GPUPlan, count)
+	for id := range count {
+		current[id] = types.GPUPlan{
+			GpuID:          id,
+			CurrentCompute: getCurrentGPUComputePartition(gpu, id),
+			CurrentMemory:  getCurrentGPUMemoryPartition(gpu, id),
+		}
+	}
+	return current, nil
+}
+
+// ApplyProfile makes a single partitioning attempt with the given profile,
+// the GPU client services are stopped for the duration of the attempt. It
+// refuses to run while the daemon holds the run lock or a partition journal
+// exists, the journal belongs to an interrupted run the daemon resumes.
+func ApplyProfile(selectedProfile string) (types.PartitionStatus, error) {
+	lock, err := lockRun()
+	if err != nil {
+		return partStatus, err
+	}
+	defer lock.Close()
+	if _, err := os.Stat(journalFileFromEnv()); err == nil {
+		return partStatus, fmt.Errorf("partition journal %v exists, another partition run was interrupted", journalFileFromEnv())
+	}
+	cfg, err := LoadConfig(ConfigFilePath())
+	if err != nil {
+		return partStatus, err
+	}
+	beginJournal(selectedProfile, cfg.Services)
+	utils.StopServiceHandler(cfg.Services)
+	defer finishJournal()
+	defer utils.StartServiceHandler(cfg.Services)
+
+	if err := PartitionGPU(context.Background(), selectedProfile); err != nil {
+		return partStatus, err
+	}
+	if partStatus.FinalStatus != "Success" {
+		return partStatus, errors.New(partStatus.Reason)
+	}
+	return partStatus, nil
+}
+
+// ReadNodeState returns the status recorded in a standalone state file
+func ReadNodeState(path string) (*types.NodeState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var state types.NodeState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
@@ -0,0 +1,182 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// buildPartitionPlan compares the current modes of the GPUs with a validated
+// profile, GPUs are only read so the plan can be computed at any time
+func buildPartitionPlan(gpu backend.GPUBackend, selectedProfile string, profile *partition_pb.GPUConfigProfile, totalGPUCount int) (types.PartitionPlan, error) {
+	plan := types.PartitionPlan{SelectedProfile: selectedProfile, GPUs: []types.GPUPlan{}, ServicesToStop: []string{}}
+	gpuIDs := createGPUIDList(profile.Filters.Id, totalGPUCount)
+	idx := 0
+	for _, p := range profile.Profiles {
+		for range p.NumGPUsAssigned {
+			gpuID := gpuIDs[idx]
+			idx++
+			processorType, err := gpu.GetProcessorType(gpuID)
+			if err != nil {
+				return plan, err
+			}
+			if processorType != backend.ProcessorTypeAMDGPU {
+				log.Printf("GPU ID %v: expected AMDSMI_PROCESSOR_TYPE_AMD_GPU device type, skipping", gpuID)
+				continue
+			}
+			gpuPlan := types.GPUPlan{
+				GpuID:          gpuID,
+				PartitionType:  p.ComputePartition + "-" + p.MemoryPartition,
+				CurrentCompute: getCurrentGPUComputePartition(gpu, gpuID),
+				CurrentMemory:  getCurrentGPUMemoryPartition(gpu, gpuID),
+				TargetCompute:  p.ComputePartition,
+				TargetMemory:   p.MemoryPartition,
+			}
+			gpuPlan.ComputeChange = gpuPlan.CurrentCompute != gpuPlan.TargetCompute
+			if p.AcceleratorProfile != nil {
+				profiles, err := gpu.GetAcceleratorProfiles(gpuID)
+				if err != nil {
+					return plan, err
+				}
+				target, err := resolveAcceleratorProfile(toAcceleratorProfiles(profiles), p)
+				if err != nil {
+					return plan, fmt.Errorf("GPU ID %v: %v", gpuID, err)
+				}
+				current, err := gpu.GetAcceleratorProfileIndex(gpuID)
+				if err != nil {
+					return plan, err
+				}
+				gpuPlan.TargetAcceleratorProfile = &target.Index
+				gpuPlan.CurrentAcceleratorProfile = &current
+				gpuPlan.ComputeChange = current != target.Index
+			}
+			gpuPlan.MemoryChange = gpuPlan.CurrentMemory != gpuPlan.TargetMemory
+			gpuPlan.DriverReloadNeeded = gpuPlan.MemoryChange
+			if err := planGPUSettings(gpu, gpuID, p, &gpuPlan); err != nil {
+				return plan, err
+			}
+			plan.GPUs = append(plan.GPUs, gpuPlan)
+		}
+	}
+	return plan, nil
+}
+
+// PlanProfile validates a profile of the config and returns the partition
+// change it would make on each GPU without touching the GPUs
+func PlanProfile(selectedProfile string) (types.PartitionPlan, error) {
+	plan := types.PartitionPlan{SelectedProfile: selectedProfile}
+	path := ConfigFilePath()
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return plan, err
+	}
+	profile, exists := cfg.Profiles.ProfilesList[selectedProfile]
+	if !exists || profile == nil {
+		return plan, fmt.Errorf("profile %v not found in %v", selectedProfile, path)
+	}
+	if err := profileConfigError(cfg, selectedProfile); err != nil {
+		return plan, err
+	}
+	normalizeProfile(profile)
+
+	gpu, err := initGPUBackend()
+	if err != nil {
+		return plan, err
+	}
+	defer shutDownAMDSMI(gpu)
+	count, err := gpu.GetGPUCount()
+	if err != nil {
+		return plan, err
+	}
+	if err := validateProfile(selectedProfile, profile, count); err != nil {
+		return plan, err
+	}
+	if err := validateCapabilities(selectedProfile, profile, count, discoverCapabilities(gpu, count)); err != nil {
+		return plan, err
+	}
+	plan, err = buildPartitionPlan(gpu, selectedProfile, profile, count)
+	if err != nil {
+		return plan, err
+	}
+	if plan.PartitionNeeded() {
+		plan.ServicesToStop = cfg.Services
+	}
+	return plan, nil
+}
+
+// planOnlyRequested reports whether partitioning is limited to publishing the
+// plan, set through the dcm.amd.com/plan-only node annotation
+func planOnlyRequested() bool {
+	if IsStandaloneMode() {
+		return standalone.PlanOnly
+	}
+	if nodeName == "" {
+		return false
+	}
+	annotations, err := kc.GetNodeAnnotations(nodeName)
+	if err != nil {
+		log_e.Errorf("Failed to get node annotations: %v", err)
+		return false
+	}
+	return annotations[globals.PlanOnlyAnnotationKey] == "true"
+}
+
+// publishPartitionPlan computes the plan of a profile and publishes it as
+// an event and node annotation without applying it
+func publishPartitionPlan(selectedProfile string) {
+	log.Printf("Plan only mode, computing the partition plan for profile %v", selectedProfile)
+	plan, err := PlanProfile(selectedProfile)
+	if err != nil {
+		log_e.Errorf("Failed to compute the partition plan: %v", err)
+		partStatus.SelectedProfile = selectedProfile
+		partStatus.GPUStatus = nil
+		partStatus.FinalStatus = "Failure"
+		partStatus.Reason = fmt.Sprintf("Partition plan failed with reason: %v", err)
+		generateK8sEvent(err, globals.K8EventPartitionPlanFailed, partStatus)
+		return
+	}
+
+	planBytes, err := json.Marshal(plan)
+	if err != nil {
+		log_e.Errorf("failed to marshal partition plan %+v err %+v", plan, err)
+		return
+	}
+	log.Printf("Partition plan: %v", string(planBytes))
+	if IsStandaloneMode() {
+		updateStandaloneState(func(s *types.NodeState) {
+			s.LastEvent = globals.K8EventPartitionPlan
+			s.Plan = &plan
+		})
+		return
+	}
+	if nodeName == "" {
+		log.Printf("Not a k8s deployment, skipping event %v", globals.K8EventPartitionPlan)
+		return
+	}
+	if err := kc.AddNodeAnnotation(nodeName, globals.PlanAnnotationKey, string(planBytes)); err != nil {
+		log_e.Errorf("Error adding partition plan node annotation: %v", err)
+	}
+	createK8sEvent(nil, globals.K8EventPartitionPlan, string(planBytes))
+}
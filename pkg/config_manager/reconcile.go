@@ -0,0 +1,159 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+var (
+	stateLabelMu     sync.Mutex
+	lastProfileState string
+	// set while a retry loop partitions the GPUs, drift is not checked then
+	partitionRunning atomic.Bool
+)
+
+// recordProfileState remembers the last profile state DCM published
+func recordProfileState(state string) {
+	stateLabelMu.Lock()
+	defer stateLabelMu.Unlock()
+	lastProfileState = state
+}
+
+func currentProfileState() string {
+	stateLabelMu.Lock()
+	defer stateLabelMu.Unlock()
+	return lastProfileState
+}
+
+// reconcileIntervalFromEnv returns the drift check interval, 0 disables the
+// reconciler
+func reconcileIntervalFromEnv() time.Duration {
+	return envDuration(globals.ReconcileIntervalEnv, globals.DefaultReconcileInterval)
+}
+
+// driftPolicyFromEnv returns the drift policy, drift is only reported by
+// default
+func driftPolicyFromEnv() string {
+	return envChoice(globals.DriftPolicyEnv, globals.DriftPolicyReport, globals.DriftPolicyReport, globals.DriftPolicyReapply)
+}
+
+// driftStatus lists the GPUs of a plan that no longer match the profile
+func driftStatus(plan types.PartitionPlan) []types.GPUPartitionStatus {
+	drifted := []types.GPUPartitionStatus{}
+	for _, gpuPlan := range plan.GPUs {
+		changes := []string{}
+		if gpuPlan.ComputeChange {
+			changes = append(changes, fmt.Sprintf("compute partition %v, expected %v", gpuPlan.CurrentCompute, gpuPlan.TargetCompute))
+		}
+		if gpuPlan.MemoryChange {
+			changes = append(changes, fmt.Sprintf("memory partition %v, expected %v", gpuPlan.CurrentMemory, gpuPlan.TargetMemory))
+		}
+		if gpuPlan.SettingsChange() {
+			changes = append(changes, "GPU settings differ from the profile")
+		}
+		if len(changes) == 0 {
+			continue
+		}
+		drifted = append(drifted, types.GPUPartitionStatus{
+			GpuID:         gpuPlan.GpuID,
+			PartitionType: gpuPlan.PartitionType,
+			Status:        "Drifted",
+			Message:       strings.Join(changes, "; "),
+		})
+	}
+	return drifted
+}
+
+// checkDrift compares the GPUs against the selected profile once it was
+// applied and acts on drift according to the policy
+func checkDrift(policy string) {
+	state := currentProfileState()
+	if partitionRunning.Load() || (state != "success" && state != "drifted") {
+		return
+	}
+	// skip the tick instead of queueing behind a partition run
+	if backendBusy() {
+		return
+	}
+	selectedProfile, err := GetPartitionProfile()
+	if err != nil || selectedProfile == "" {
+		return
+	}
+	plan, err := PlanProfile(selectedProfile)
+	if err != nil {
+		log_e.Errorf("Drift check of profile %v failed: %v", selectedProfile, err)
+		return
+	}
+	// a run that started meanwhile owns the profile state
+	if partitionRunning.Load() || currentProfileState() != state {
+		return
+	}
+	drifted := driftStatus(plan)
+	if len(drifted) == 0 {
+		if state == "drifted" {
+			log.Printf("GPUs match profile %v again", selectedProfile)
+			setProfileStateLabel("success")
+		}
+		return
+	}
+	for _, gpu := range drifted {
+		log.Printf("GPU ID %v drifted from profile %v: %v", gpu.GpuID, selectedProfile, gpu.Message)
+	}
+	if policy == globals.DriftPolicyReapply {
+		TriggerRetryLoop(selectedProfile, "drift reconciler")
+		return
+	}
+	if state == "drifted" {
+		return
+	}
+	status := types.PartitionStatus{
+		SelectedProfile: selectedProfile,
+		FinalStatus:     "Drifted",
+		Reason:          fmt.Sprintf("%d GPUs no longer match profile %v", len(drifted), selectedProfile),
+		GPUStatus:       drifted,
+	}
+	setProfileStateLabel("drifted")
+	generateK8sEvent(errors.New("partition drifted"), globals.K8EventPartitionDrifted, status)
+}
+
+// StartDriftReconciler periodically checks whether the GPUs still match the
+// applied profile, e.g. after a manual amd-smi call or a driver reload
+func StartDriftReconciler() {
+	interval := reconcileIntervalFromEnv()
+	if interval == 0 {
+		log.Printf("Drift reconciler disabled")
+		return
+	}
+	policy := driftPolicyFromEnv()
+	log.Printf("Starting drift reconciler, interval %v, policy %v", interval, policy)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		checkDrift(policy)
+	}
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"log"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+)
+
+// amdsmi reports and sets power caps in microwatts, clocks are in MHz
+const microWattsPerWatt = 1000000
+
+// planGPUSettings reads the GPU settings requested by a partition config and
+// records the ones that differ in the plan
+func planGPUSettings(gpu backend.GPUBackend, gpuID int, p *partition_pb.ProfileConfig, gpuPlan *types.GPUPlan) error {
+	if p.PowerCapWatts != 0 {
+		info, err := gpu.GetPowerCapInfo(gpuID)
+		if err != nil {
+			return err
+		}
+		gpuPlan.CurrentPowerCapWatts = uint32(info.PowerCap / microWattsPerWatt)
+		gpuPlan.TargetPowerCapWatts = p.PowerCapWatts
+		gpuPlan.PowerCapChange = gpuPlan.CurrentPowerCapWatts != gpuPlan.TargetPowerCapWatts
+	}
+	if p.PerfLevel != "" {
+		level, err := gpu.GetPerfLevel(gpuID)
+		if err != nil {
+			return err
+		}
+		gpuPlan.CurrentPerfLevel = level
+		gpuPlan.TargetPerfLevel = p.PerfLevel
+		gpuPlan.DeterminismClockMHz = p.DeterminismClockMHz
+		gpuPlan.PerfLevelChange = level != p.PerfLevel
+		if p.PerfLevel == backend.PerfLevelDeterminism && !gpuPlan.PerfLevelChange {
+			// the soft maximum may differ while the level is already set
+			info, err := gpu.GetClockInfo(gpuID, backend.ClockGFX)
+			if err != nil {
+				return err
+			}
+			gpuPlan.PerfLevelChange = info.Current.Max != uint64(p.DeterminismClockMHz)
+		}
+	}
+	for _, clock := range p.Clocks {
+		info, err := gpu.GetClockInfo(gpuID, clock.Type)
+		if err != nil {
+			return err
+		}
+		clockPlan := types.ClockPlan{
+			Type:    clock.Type,
+			Current: toClockRange(info.Current),
+			Target:  types.ClockRange{MinMHz: clock.MinMHz, MaxMHz: clock.MaxMHz},
+		}
+		clockPlan.Change = clock.MinMHz != 0 && clock.MinMHz != clockPlan.Current.MinMHz ||
+			clock.MaxMHz != 0 && clock.MaxMHz != clockPlan.Current.MaxMHz
+		gpuPlan.Clocks = append(gpuPlan.Clocks, clockPlan)
+	}
+	if p.PowerProfile != "" {
+		info, err := gpu.GetPowerProfile(gpuID)
+		if err != nil {
+			return err
+		}
+		gpuPlan.CurrentPowerProfile = info.Current
+		gpuPlan.TargetPowerProfile = p.PowerProfile
+		gpuPlan.PowerProfileChange = info.Current != p.PowerProfile
+	}
+	if p.ProcessIsolation != nil {
+		isolation, err := gpu.GetProcessIsolation(gpuID)
+		if err != nil {
+			return err
+		}
+		target := p.GetProcessIsolation()
+		gpuPlan.CurrentProcessIsolation = isolation
+		gpuPlan.TargetProcessIsolation = &target
+		gpuPlan.ProcessIsolationChange = isolation != target
+	}
+	return nil
+}
+
+func toClockRange(r backend.ClockRange) types.ClockRange {
+	return types.ClockRange{MinMHz: uint32(r.Min), MaxMHz: uint32(r.Max)}
+}
+
+// applyGPUSettings applies the settings of a plan that differ from the GPU
+// and records the resulting values in the GPU status
+func applyGPUSettings(gpu backend.GPUBackend, gpuPlan types.GPUPlan, status *types.GPUPartitionStatus) error {
+	gpuID := gpuPlan.GpuID
+	if gpuPlan.TargetPowerCapWatts != 0 {
+		if gpuPlan.PowerCapChange {
+			log.Printf("GPU ID %v: setting power cap from %vW to %vW", gpuID, gpuPlan.CurrentPowerCapWatts, gpuPlan.TargetPowerCapWatts)
+			if err := gpu.SetPowerCap(gpuID, uint64(gpuPlan.TargetPowerCapWatts)*microWattsPerWatt); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("GPU ID %v: power cap already %vW", gpuID, gpuPlan.TargetPowerCapWatts)
+		}
+		info, err := gpu.GetPowerCapInfo(gpuID)
+		if err != nil {
+			return err
+		}
+		status.PowerCapWatts = uint32(info.PowerCap / microWattsPerWatt)
+	}
+	// the performance level goes first, clock limits only stick in the
+	// MANUAL level on some GPUs
+	if gpuPlan.TargetPerfLevel != "" {
+		if gpuPlan.PerfLevelChange {
+			log.Printf("GPU ID %v: setting performance level from %v to %v", gpuID, gpuPlan.CurrentPerfLevel, gpuPlan.TargetPerfLevel)
+			var err error
+			if gpuPlan.TargetPerfLevel == backend.PerfLevelDeterminism {
+				err = gpu.SetPerfDeterminismMode(gpuID, uint64(gpuPlan.DeterminismClockMHz))
+			} else {
+				err = gpu.SetPerfLevel(gpuID, gpuPlan.TargetPerfLevel)
+			}
+			if err != nil {
+				return err
+			}
+		} else {
+			log.Printf("GPU ID %v: performance level already %v", gpuID, gpuPlan.TargetPerfLevel)
+		}
+		level, err := gpu.GetPerfLevel(gpuID)
+		if err != nil {
+			return err
+		}
+		status.PerfLevel = level
+	}
+	for _, clock := range gpuPlan.Clocks {
+		if clock.Change {
+			log.Printf("GPU ID %v: setting %v clock range from %v - %v MHz to %v - %v MHz", gpuID, clock.Type,
+				clock.Current.MinMHz, clock.Current.MaxMHz, clock.Target.MinMHz, clock.Target.MaxMHz)
+			target := backend.ClockRange{Min: uint64(clock.Target.MinMHz), Max: uint64(clock.Target.MaxMHz)}
+			if err := gpu.SetClockRange(gpuID, clock.Type, target); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("GPU ID %v: %v clock range already set", gpuID, clock.Type)
+		}
+		info, err := gpu.GetClockInfo(gpuID, clock.Type)
+		if err != nil {
+			return err
+		}
+		if status.Clocks == nil {
+			status.Clocks = map[string]types.ClockRange{}
+		}
+		status.Clocks[clock.Type] = toClockRange(info.Current)
+	}
+	if gpuPlan.TargetPowerProfile != "" {
+		if gpuPlan.PowerProfileChange {
+			log.Printf("GPU ID %v: setting power profile from %v to %v", gpuID, gpuPlan.CurrentPowerProfile, gpuPlan.TargetPowerProfile)
+			if err := gpu.SetPowerProfile(gpuID, gpuPlan.TargetPowerProfile); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("GPU ID %v: power profile already %v", gpuID, gpuPlan.TargetPowerProfile)
+		}
+		info, err := gpu.GetPowerProfile(gpuID)
+		if err != nil {
+			return err
+		}
+		status.PowerProfile = info.Current
+	}
+	// isolation is set per partition, so it goes after the partitions are
+	// final and is read again, the plan saw the partitions before the
+	// compute partition changed
+	if gpuPlan.TargetProcessIsolation != nil {
+		isolation, err := gpu.GetProcessIsolation(gpuID)
+		if err != nil {
+			return err
+		}
+		if isolation != *gpuPlan.TargetProcessIsolation {
+			log.Printf("GPU ID %v: setting process isolation to %v", gpuID, *gpuPlan.TargetProcessIsolation)
+			if err := gpu.SetProcessIsolation(gpuID, *gpuPlan.TargetProcessIsolation); err != nil {
+				return err
+			}
+			if isolation, err = gpu.GetProcessIsolation(gpuID); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("GPU ID %v: process isolation already %v", gpuID, *gpuPlan.TargetProcessIsolation)
+		}
+		status.ProcessIsolation = &isolation
+	}
+	return nil
+}
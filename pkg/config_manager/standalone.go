@@ -0,0 +1,193 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	"github.com/fsnotify/fsnotify"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// StandaloneConfig holds the settings used when DCM runs outside k8s
+type StandaloneConfig struct {
+	// profile to apply, takes precedence over ProfileFile
+	Profile string
+	// file holding the name of the profile to apply
+	ProfileFile string
+	// file the partition status is written to
+	StateFile string
+	// only write the partition plan of the profile to the state file
+	PlanOnly bool
+}
+
+var (
+	standalone   *StandaloneConfig
+	stateMu      sync.Mutex
+	currentState types.NodeState
+)
+
+// EnableStandaloneMode makes DCM select the profile from a local file or
+// flag and report its status to a local state file instead of the node
+func EnableStandaloneMode(cfg StandaloneConfig) {
+	if cfg.ProfileFile == "" {
+		cfg.ProfileFile = globals.StandaloneProfileFilePath
+	}
+	if cfg.StateFile == "" {
+		cfg.StateFile = globals.StandaloneStateFilePath
+	}
+	standalone = &cfg
+	log.Printf("Standalone mode enabled, profile file: %v, state file: %v", cfg.ProfileFile, cfg.StateFile)
+}
+
+func IsStandaloneMode() bool {
+	return standalone != nil
+}
+
+func getStandaloneProfile() (string, error) {
+	if standalone.Profile != "" {
+		log.Printf("Selected profile name: %+v\n", standalone.Profile)
+		return standalone.Profile, nil
+	}
+	data, err := os.ReadFile(standalone.ProfileFile)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No profile selected, please write a profile name from the config to %v to begin partitioning\n", standalone.ProfileFile)
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	selectedProfile := strings.TrimSpace(string(data))
+	if selectedProfile == "" {
+		log.Printf("No profile selected, %v is empty\n", standalone.ProfileFile)
+		return "", nil
+	}
+	log.Printf("Selected profile name: %+v\n", selectedProfile)
+	return selectedProfile, nil
+}
+
+// selectStandaloneProfile writes the profile to the profile file and reports
+// whether the selection changed
+func selectStandaloneProfile(profile string) (bool, error) {
+	if standalone.Profile != "" {
+		if standalone.Profile != profile {
+			return false, fmt.Errorf("profile %v is selected by the -profile flag", standalone.Profile)
+		}
+		return false, nil
+	}
+	if data, err := os.ReadFile(standalone.ProfileFile); err == nil && strings.TrimSpace(string(data)) == profile {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(standalone.ProfileFile), 0755); err != nil {
+		return false, err
+	}
+	// a rename is seen by the profile file watcher as a single event
+	tmpFile := standalone.ProfileFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(profile+"\n"), 0644); err != nil {
+		return false, err
+	}
+	return true, os.Rename(tmpFile, standalone.ProfileFile)
+}
+
+// updateStandaloneState merges the update into the state file, the file is
+// replaced atomically so readers never see a partial write
+func updateStandaloneState(update func(state *types.NodeState)) {
+	stateMu.Lock()
+	defer stateMu.Unlock()
+
+	update(&currentState)
+	currentState.LastUpdated = time.Now().UTC()
+	data, err := json.MarshalIndent(currentState, "", "  ")
+	if err != nil {
+		log_e.Errorf("failed to marshal node state %+v err %+v", currentState, err)
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(standalone.StateFile), 0755); err != nil {
+		log_e.Errorf("failed to create state file directory: %v", err)
+		return
+	}
+	tmpFile := standalone.StateFile + ".tmp"
+	if err := os.WriteFile(tmpFile, data, 0644); err != nil {
+		log_e.Errorf("failed to write state file %v: %v", tmpFile, err)
+		return
+	}
+	if err := os.Rename(tmpFile, standalone.StateFile); err != nil {
+		log_e.Errorf("failed to update state file %v: %v", standalone.StateFile, err)
+	}
+}
+
+// StartProfileFileWatcher re-partitions whenever the standalone profile file
+// changes, the directory is watched so the file may be created later
+func StartProfileFileWatcher() {
+	if !IsStandaloneMode() || standalone.Profile != "" {
+		return
+	}
+	profileFile := filepath.Clean(standalone.ProfileFile)
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.Print(err)
+		setComponentHealth(componentProfileFileWatcher, err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(filepath.Dir(profileFile)); err != nil {
+		log.Print(err)
+		setComponentHealth(componentProfileFileWatcher, err)
+		return
+	}
+	log.Printf("starting profile file watcher for %v", profileFile)
+	setComponentHealth(componentProfileFileWatcher, nil)
+	defer setComponentHealth(componentProfileFileWatcher, errWatcherStopped)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				log.Print("Event channel closed")
+				return
+			}
+			if filepath.Clean(event.Name) != profileFile || !event.Has(fsnotify.Create|fsnotify.Write) {
+				continue
+			}
+			log.Printf("Detected changes in %v, re-reading the file.", profileFile)
+			selectedProfile, err := GetPartitionProfile()
+			if err != nil {
+				log_e.Errorf("err: %+v", err)
+			}
+			if selectedProfile != "" && !takeAppliedSelection(selectedProfile) {
+				TriggerRetryLoop(selectedProfile, "profile file watcher")
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				log.Print("Event channel closed, error")
+				return
+			}
+			log.Print("Error:", err)
+		}
+	}
+}
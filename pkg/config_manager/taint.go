@@ -0,0 +1,101 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	log_e "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+)
+
+// taint the DCM daemonset tolerates, see helm-charts/templates/daemonsets.yaml
+var dcmTaint = v1.Taint{Key: globals.DCMTaintKey, Value: globals.DCMTaintValue, Effect: v1.TaintEffectNoExecute}
+
+// taintPolicyFromEnv returns the taint policy, the taint is left to the user
+// by default
+func taintPolicyFromEnv() string {
+	return envChoice(globals.TaintPolicyEnv, globals.TaintPolicyNone, globals.TaintPolicyNone, globals.TaintPolicyManage, globals.TaintPolicyRequire)
+}
+
+// hasDCMTaint reports whether the taints include the amd-dcm NoExecute taint
+func hasDCMTaint(taints []v1.Taint) bool {
+	for _, t := range taints {
+		if t.MatchTaint(&dcmTaint) {
+			return true
+		}
+	}
+	return false
+}
+
+// prepareNodeTaint handles the amd-dcm taint before the GPUs are partitioned
+// with a profile. proceed is false when partitioning has to be refused,
+// tainted reports whether the taint was added and has to be removed once
+// partitioning ends. Runs that change no partition leave the node as is.
+func prepareNodeTaint(selectedProfile string) (tainted bool, proceed bool) {
+	policy := taintPolicyFromEnv()
+	if policy == globals.TaintPolicyNone || nodeName == "" {
+		return false, true
+	}
+	if plan, err := PlanProfile(selectedProfile); err == nil && !plan.PartitionNeeded() {
+		log.Printf("No partition change for profile %v, leaving the %v taint as is", selectedProfile, globals.DCMTaintKey)
+		return false, true
+	}
+
+	if policy == globals.TaintPolicyRequire {
+		taints, err := kc.GetNodeTaints(nodeName)
+		if err == nil && hasDCMTaint(taints) {
+			return false, true
+		}
+		partStatus.SelectedProfile = selectedProfile
+		partStatus.GPUStatus = nil
+		partStatus.FinalStatus = "Failure"
+		if err != nil {
+			partStatus.Reason = fmt.Sprintf("Failed to get the taints of node %v: %v", nodeName, err)
+		} else {
+			partStatus.Reason = fmt.Sprintf("Node is not tainted with %v=%v:%v, partitioning refused", dcmTaint.Key, dcmTaint.Value, dcmTaint.Effect)
+		}
+		log_e.Errorf("%v", partStatus.Reason)
+		generateK8sEvent(errors.New("node not tainted"), globals.K8EventNoPartition, partStatus)
+		setProfileStateLabel("failure")
+		return false, false
+	}
+
+	added, err := kc.AddNodeTaint(nodeName, dcmTaint)
+	if err != nil {
+		log_e.Errorf("Failed to taint node %v: %v", nodeName, err)
+		return false, true
+	}
+	if added {
+		log.Printf("Node %v tainted with %v=%v:%v for partitioning", nodeName, dcmTaint.Key, dcmTaint.Value, dcmTaint.Effect)
+		journalTaint(true)
+	}
+	return added, true
+}
+
+// removeNodeTaint removes the amd-dcm taint added for partitioning
+func removeNodeTaint() {
+	if err := kc.RemoveNodeTaint(nodeName, dcmTaint); err != nil {
+		log_e.Errorf("Failed to remove the %v taint of node %v: %v", dcmTaint.Key, nodeName, err)
+		return
+	}
+	log.Printf("Removed the %v taint of node %v", dcmTaint.Key, nodeName)
+	journalTaint(false)
+}
@@ -0,0 +1,302 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	partition_pb "github.com/ROCm/device-config-manager/gen/partition"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// severities of the validation issues, profiles with errors cannot be applied
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+const profilesPath = "gpu-config-profiles"
+
+var (
+	configIssuesMu sync.Mutex
+	// issues reported for the last loaded config, used to report changes only
+	lastConfigIssues string
+)
+
+// checkProfile runs every check on a profile and returns all the issues
+// found, the checks do not depend on each other so none hides another
+func checkProfile(name string, profile *partition_pb.GPUConfigProfile, totalGPUCount int) []types.ValidationIssue {
+	prefix := fmt.Sprintf("%s.%s", profilesPath, name)
+	issues := []types.ValidationIssue{}
+	report := func(severity, path, format string, args ...interface{}) {
+		issues = append(issues, types.ValidationIssue{
+			Path:     path,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if profile == nil || len(profile.Profiles) == 0 {
+		report(SeverityError, prefix+".profiles", "profile does not contain any partition config")
+		return issues
+	}
+
+	skipped := []uint32{}
+	if profile.Filters != nil {
+		skipped = profile.Filters.Id
+	}
+	if len(skipped) > totalGPUCount {
+		report(SeverityError, prefix+".skippedGPUs.ids", "%d GPU IDs are skipped but the node only has %d GPUs", len(skipped), totalGPUCount)
+	}
+	seen := make(map[uint32]bool)
+	for i, id := range skipped {
+		path := fmt.Sprintf("%s.skippedGPUs.ids[%d]", prefix, i)
+		if int(id) >= totalGPUCount {
+			report(SeverityError, path, "invalid GPU ID %d, valid GPU indices are 0 - %d", id, totalGPUCount-1)
+		}
+		if seen[id] {
+			report(SeverityError, path, "GPU ID %d is listed more than once", id)
+		}
+		seen[id] = true
+	}
+
+	totalAssigned := 0
+	memoryPartition := profile.Profiles[0].GetMemoryPartition()
+	for i, p := range profile.Profiles {
+		path := fmt.Sprintf("%s.profiles[%d]", prefix, i)
+		if p == nil {
+			report(SeverityError, path, "empty partition config")
+			continue
+		}
+		totalAssigned += int(p.NumGPUsAssigned)
+		if !ValidateList(p.ComputePartition, globals.ValidComputePartitions) {
+			report(SeverityError, path+".computePartition", "invalid compute type %q, valid types are %v", p.ComputePartition, globals.ValidComputePartitions)
+		}
+		if !ValidateList(p.MemoryPartition, globals.ValidMemoryPartitions) {
+			report(SeverityError, path+".memoryPartition", "invalid memory type %q, valid types are %v", p.MemoryPartition, globals.ValidMemoryPartitions)
+		} else if p.MemoryPartition != memoryPartition && ValidateList(memoryPartition, globals.ValidMemoryPartitions) {
+			report(SeverityError, path+".memoryPartition", "memory type %v differs from %v, all partition configs of a profile must use the same memory type", p.MemoryPartition, memoryPartition)
+		}
+		if ref := p.AcceleratorProfile; ref != nil && ref.Type != "" && ref.Type != p.ComputePartition {
+			report(SeverityError, path+".acceleratorProfile.type", "accelerator profile type %v differs from compute type %v", ref.Type, p.ComputePartition)
+		}
+		clockTypes := make(map[string]bool)
+		for ci, clock := range p.Clocks {
+			clockPath := fmt.Sprintf("%s.clocks[%d]", path, ci)
+			switch {
+			case clock.Type != backend.ClockGFX && clock.Type != backend.ClockMEM:
+				report(SeverityError, clockPath+".type", "invalid clock type %q, valid types are [%v %v]", clock.Type, backend.ClockGFX, backend.ClockMEM)
+			case clockTypes[clock.Type]:
+				report(SeverityError, clockPath+".type", "%v clock is listed more than once", clock.Type)
+			}
+			clockTypes[clock.Type] = true
+			if clock.MinMHz == 0 && clock.MaxMHz == 0 {
+				report(SeverityError, clockPath, "neither minMHz nor maxMHz is set")
+			} else if clock.MinMHz != 0 && clock.MaxMHz != 0 && clock.MinMHz > clock.MaxMHz {
+				report(SeverityError, clockPath, "minMHz %v is above maxMHz %v", clock.MinMHz, clock.MaxMHz)
+			}
+		}
+		if p.PerfLevel != "" && !ValidateList(p.PerfLevel, backend.PerfLevels) {
+			report(SeverityError, path+".perfLevel", "invalid performance level %q, valid levels are %v", p.PerfLevel, backend.PerfLevels)
+		}
+		if p.PerfLevel == backend.PerfLevelDeterminism && p.DeterminismClockMHz == 0 {
+			report(SeverityError, path+".determinismClockMHz", "performance level %v needs the GFX clock soft maximum", p.PerfLevel)
+		} else if p.PerfLevel != backend.PerfLevelDeterminism && p.DeterminismClockMHz != 0 {
+			report(SeverityError, path+".determinismClockMHz", "only used with performance level %v", backend.PerfLevelDeterminism)
+		}
+		if p.PowerProfile != "" && !ValidateList(p.PowerProfile, backend.PowerProfiles) {
+			report(SeverityError, path+".powerProfile", "invalid power profile %q, valid profiles are %v", p.PowerProfile, backend.PowerProfiles)
+		}
+		if p.NumGPUsAssigned == 0 {
+			report(SeverityWarning, path+".numGPUsAssigned", "no GPUs assigned, the partition config has no effect")
+		}
+	}
+
+	if totalAssigned+len(skipped) != totalGPUCount {
+		report(SeverityError, prefix+".profiles", "numGPUsAssigned adds up to %d and %d GPUs are skipped, the node has %d GPUs", totalAssigned, len(skipped), totalGPUCount)
+	}
+	return issues
+}
+
+// ValidateProfiles checks every profile of a config against the GPU count of
+// the node, issues are ordered by profile name
+func ValidateProfiles(profiles *partition_pb.GPUConfigProfiles, totalGPUCount int) []types.ValidationIssue {
+	issues := []types.ValidationIssue{}
+	if len(profiles.ProfilesList) == 0 {
+		issues = append(issues, types.ValidationIssue{Path: profilesPath, Severity: SeverityError, Message: "config does not contain any profile"})
+	}
+	for _, name := range ProfileNames(profiles) {
+		issues = append(issues, checkProfile(name, profiles.ProfilesList[name], totalGPUCount)...)
+	}
+	return issues
+}
+
+// ValidateConfig returns the fields missing in the profiles of a config
+// along with the issues found by ValidateProfiles and CheckCapabilities,
+// caps may be nil when the GPUs cannot be queried
+func ValidateConfig(cfg *Config, totalGPUCount int, caps []types.GPUCapabilities) []types.ValidationIssue {
+	issues := []types.ValidationIssue{}
+	for _, name := range ProfileNames(cfg.Profiles) {
+		for _, cerr := range cfg.ProfileErrors[name] {
+			issues = append(issues, types.ValidationIssue{
+				Path:     cerr.Path,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("%s at line %d column %d", cerr.Message, cerr.Line, cerr.Column),
+			})
+		}
+	}
+	issues = append(issues, ValidateProfiles(cfg.Profiles, totalGPUCount)...)
+	return append(issues, CheckCapabilities(cfg.Profiles, totalGPUCount, caps)...)
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []types.ValidationIssue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// validationError carries the error issues of a profile
+type validationError struct {
+	issues []types.ValidationIssue
+}
+
+func (e *validationError) Error() string {
+	msgs := []string{}
+	for _, issue := range e.issues {
+		msgs = append(msgs, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func issuesError(issues []types.ValidationIssue) error {
+	errIssues := []types.ValidationIssue{}
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			errIssues = append(errIssues, issue)
+		}
+	}
+	if len(errIssues) == 0 {
+		return nil
+	}
+	return &validationError{issues: errIssues}
+}
+
+// validationEvent picks the event reporting a profile validation error from
+// the field of its first issue
+func validationEvent(err error) string {
+	var verr *validationError
+	if !errors.As(err, &verr) {
+		return globals.K8EventInvalidProfile
+	}
+	switch {
+	case strings.HasSuffix(verr.issues[0].Path, ".computePartition"):
+		return globals.K8EventInvalidComputeType
+	case strings.HasSuffix(verr.issues[0].Path, ".memoryPartition"):
+		return globals.K8EventInvalidMemoryType
+	}
+	return globals.K8EventInvalidProfile
+}
+
+// validateProfile checks the selected profile before partitioning, all the
+// errors found are returned together
+func validateProfile(name string, profile *partition_pb.GPUConfigProfile, totalGPUCount int) error {
+	issues := checkProfile(name, profile, totalGPUCount)
+	for _, issue := range issues {
+		log.Printf("%v %v: %v", strings.ToUpper(issue.Severity), issue.Path, issue.Message)
+	}
+	if err := issuesError(issues); err != nil {
+		return err
+	}
+	normalizeProfile(profile)
+	log.Printf("Usable GPU IDs for partitioning %v", createGPUIDList(profile.Filters.Id, totalGPUCount))
+	for _, p := range profile.Profiles {
+		log.Printf("Partitioning %v devices with compute partition type %v and memory type %v", p.NumGPUsAssigned, p.ComputePartition, p.MemoryPartition)
+	}
+	log.Println("Profile validation successful")
+	return nil
+}
+
+// reportConfigIssues publishes the issues found in all profiles of the
+// config, an event is only raised when the issues change
+func reportConfigIssues(cfg *Config, totalGPUCount int, caps []types.GPUCapabilities) {
+	issues := ValidateConfig(cfg, totalGPUCount, caps)
+	msgbytes, err := json.Marshal(issues)
+	if err != nil {
+		log_e.Errorf("failed to marshal validation issues %+v err %+v", issues, err)
+		return
+	}
+
+	configIssuesMu.Lock()
+	defer configIssuesMu.Unlock()
+	if string(msgbytes) == lastConfigIssues {
+		return
+	}
+	lastConfigIssues = string(msgbytes)
+
+	for _, issue := range issues {
+		log.Printf("Config %v %v: %v", issue.Severity, issue.Path, issue.Message)
+	}
+	if IsStandaloneMode() {
+		updateStandaloneState(func(s *types.NodeState) {
+			s.ConfigIssues = issues
+		})
+		return
+	}
+	if len(issues) == 0 {
+		log.Printf("All profiles of the config are valid")
+		return
+	}
+	if nodeName == "" {
+		log.Printf("Not a k8s deployment, skipping event %v", globals.K8EventInvalidProfilesInConfigMap)
+		return
+	}
+	var evtErr error
+	if HasErrors(issues) {
+		evtErr = errors.New("invalid profiles in configmap")
+	}
+	createK8sEvent(evtErr, globals.K8EventInvalidProfilesInConfigMap, string(msgbytes))
+}
+
+// ValidateConfigFile validates all profiles of the config file against the
+// GPUs of the node and publishes the issues and GPU capabilities found
+func ValidateConfigFile() {
+	path := ConfigFilePath()
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		log_e.Errorf("Failed to load %v: %v", path, err)
+		return
+	}
+	caps, err := GetCapabilities()
+	if err != nil {
+		log_e.Errorf("Failed to query the GPUs, skipping config validation: %v", err)
+		return
+	}
+	publishCapabilities(caps)
+	reportConfigIssues(cfg, len(caps), caps)
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	log_e "github.com/sirupsen/logrus"
+)
+
+// gpuPartitionCount returns the number of partitions the compute partition
+// mode splits the GPU into, false when it is not known for the GPU model
+func gpuPartitionCount(gpu backend.GPUBackend, gpuID int, compute string) (int, bool) {
+	info, err := gpu.GetASICInfo(gpuID)
+	if err != nil {
+		return 0, false
+	}
+	return backend.PartitionCount(backend.ASICModel(info), compute)
+}
+
+// verifyPartitions checks that amdsmi and the KFD topology under sysfsRoot
+// expose the partitions the plan implies on each GPU, e.g. 8 for CPX on a
+// MI300X. It returns the mismatch of each GPU by plan index, empty when the
+// GPU matches or its model is not known. The topology is skipped when the KFD
+// is not visible.
+func verifyPartitions(gpu backend.GPUBackend, plan types.PartitionPlan, sysfsRoot string) []string {
+	topology, err := backend.ReadTopology(sysfsRoot)
+	if err != nil {
+		log.Printf("KFD topology not readable under %v, only checking the amdsmi processor handles: %v", sysfsRoot, err)
+	}
+	mismatches := make([]string, len(plan.GPUs))
+	for idx, gpuPlan := range plan.GPUs {
+		expected, ok := gpuPartitionCount(gpu, gpuPlan.GpuID, gpuPlan.TargetCompute)
+		if !ok {
+			log.Printf("GPU ID %v: partition count of %v not known for the GPU model, skipping the verification", gpuPlan.GpuID, gpuPlan.TargetCompute)
+			continue
+		}
+		found := []string{}
+		handles, err := gpu.GetProcessorCount(gpuPlan.GpuID)
+		if err != nil {
+			log_e.Errorf("GPU ID %v: failed to get the processor handles: %v", gpuPlan.GpuID, err)
+		} else if handles != expected {
+			found = append(found, fmt.Sprintf("%d amdsmi processor handles", handles))
+		}
+		if topology != nil {
+			address, err := gpu.GetPCIAddress(gpuPlan.GpuID)
+			if err != nil {
+				log_e.Errorf("GPU ID %v: failed to get the PCI address: %v", gpuPlan.GpuID, err)
+			} else {
+				socket := topology[address]
+				if socket.KFDNodes != expected {
+					found = append(found, fmt.Sprintf("%d KFD nodes", socket.KFDNodes))
+				}
+				if socket.RenderNodes != expected {
+					found = append(found, fmt.Sprintf("%d render nodes", socket.RenderNodes))
+				}
+			}
+		}
+		if len(found) != 0 {
+			mismatches[idx] = fmt.Sprintf("%v implies %d partitions, found %v", gpuPlan.TargetCompute, expected, strings.Join(found, ", "))
+		}
+	}
+	return mismatches
+}
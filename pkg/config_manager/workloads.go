@@ -0,0 +1,191 @@
+/*
+Copyright (c) Advanced Micro Devices, Inc. All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the \"License\");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+     http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an \"AS IS\" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package configmanager
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/backend"
+	"github.com/ROCm/device-config-manager/pkg/amdgpu/hostproc"
+	"github.com/ROCm/device-config-manager/pkg/config_manager/globals"
+	types "github.com/ROCm/device-config-manager/pkg/config_manager/interface"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// containerGPUResources returns the AMD GPU resources a container requests,
+// limits take precedence as extended resources cannot be overcommitted
+func containerGPUResources(c *v1.Container) map[string]string {
+	resources := map[string]string{}
+	for _, list := range []v1.ResourceList{c.Resources.Requests, c.Resources.Limits} {
+		for name, quantity := range list {
+			if strings.HasPrefix(string(name), globals.GPUResourcePrefix) {
+				resources[string(name)] = quantity.String()
+			}
+		}
+	}
+	return resources
+}
+
+// gpuContainers returns the containers of the pod requesting AMD GPU
+// resources, init containers included
+func gpuContainers(pod *v1.Pod) []types.GPUContainer {
+	containers := []types.GPUContainer{}
+	for _, list := range [][]v1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range list {
+			if resources := containerGPUResources(&list[i]); len(resources) != 0 {
+				containers = append(containers, types.GPUContainer{Name: list[i].Name, Resources: resources})
+			}
+		}
+	}
+	return containers
+}
+
+// podRequestsGPU reports whether a container of the pod requests an AMD GPU
+// resource
+func podRequestsGPU(pod *v1.Pod) bool {
+	return len(gpuContainers(pod)) != 0
+}
+
+// gpuWorkloads returns the pods requesting AMD GPU resources that have not
+// finished
+func gpuWorkloads(pods []v1.Pod) []types.GPUWorkload {
+	workloads := []types.GPUWorkload{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+		containers := gpuContainers(pod)
+		if len(containers) == 0 {
+			continue
+		}
+		workload := types.GPUWorkload{Namespace: pod.Namespace, Pod: pod.Name, Containers: containers}
+		if owner := metav1.GetControllerOf(pod); owner != nil {
+			workload.Owner = owner.Kind + "/" + owner.Name
+		}
+		workloads = append(workloads, workload)
+	}
+	return workloads
+}
+
+// formatGPUWorkloads describes the workloads for the logs, e.g.
+// default/train-0 (Job/train) containers: main[amd.com/gpu=8]
+func formatGPUWorkloads(workloads []types.GPUWorkload) string {
+	if len(workloads) == 0 {
+		return "none"
+	}
+	descs := []string{}
+	for _, w := range workloads {
+		desc := w.Namespace + "/" + w.Pod
+		if w.Owner != "" {
+			desc += " (" + w.Owner + ")"
+		}
+		containers := []string{}
+		for _, c := range w.Containers {
+			resources := []string{}
+			for name, quantity := range c.Resources {
+				resources = append(resources, name+"="+quantity)
+			}
+			sort.Strings(resources)
+			containers = append(containers, fmt.Sprintf("%v[%v]", c.Name, strings.Join(resources, ",")))
+		}
+		descs = append(descs, desc+" containers: "+strings.Join(containers, " "))
+	}
+	return strings.Join(descs, "; ")
+}
+
+// gpuProcesses merges the processes amdsmi reports on the GPUs with the
+// processes holding GPU device files open under procRoot
+func gpuProcesses(gpu backend.GPUBackend, gpuCount int, procRoot string) []types.GPUProcess {
+	processes := []types.GPUProcess{}
+	index := map[int]int{}
+	add := func(holder hostproc.Holder) *types.GPUProcess {
+		if i, ok := index[holder.PID]; ok {
+			return &processes[i]
+		}
+		index[holder.PID] = len(processes)
+		processes = append(processes, types.GPUProcess{
+			PID:         holder.PID,
+			Command:     holder.Command,
+			Cgroup:      holder.Cgroup,
+			ContainerID: holder.ContainerID,
+			Devices:     holder.Devices,
+		})
+		return &processes[len(processes)-1]
+	}
+
+	holders, err := hostproc.Scan(procRoot)
+	if err != nil {
+		log.Printf("Failed to scan %v for GPU device files: %v", procRoot, err)
+	}
+	for _, holder := range holders {
+		add(holder)
+	}
+	for id := 0; id < gpuCount; id++ {
+		list, err := gpu.GetProcesses(id)
+		if err != nil {
+			log.Printf("GPU ID %v: failed to get the process list: %v", id, err)
+			continue
+		}
+		for _, p := range list {
+			_, known := index[p.PID]
+			process := add(hostproc.Describe(procRoot, p.PID))
+			if !known && process.Command == "" {
+				process.Command = p.Name
+			}
+			process.GPUs = append(process.GPUs, id)
+		}
+	}
+	sort.Slice(processes, func(i, j int) bool { return processes[i].PID < processes[j].PID })
+	return processes
+}
+
+// getGPUProcesses lists the processes holding GPUs, they are added to the
+// partition status once per attempt
+func getGPUProcesses(gpu backend.GPUBackend) []types.GPUProcess {
+	if partStatus.GPUProcesses == nil {
+		partStatus.GPUProcesses = gpuProcesses(gpu, totalGPUCount, hostproc.ProcRootFromEnv())
+	}
+	return partStatus.GPUProcesses
+}
+
+// formatGPUProcesses describes the processes for the logs, e.g.
+// 4242 python train.py (container 3f2a...) /dev/kfd
+func formatGPUProcesses(processes []types.GPUProcess) string {
+	if len(processes) == 0 {
+		return "none"
+	}
+	descs := []string{}
+	for _, p := range processes {
+		desc := fmt.Sprintf("%d %v", p.PID, p.Command)
+		if p.ContainerID != "" {
+			desc += fmt.Sprintf(" (container %.12s)", p.ContainerID)
+		}
+		if len(p.GPUs) != 0 {
+			desc += fmt.Sprintf(" GPUs %v", p.GPUs)
+		}
+		if len(p.Devices) != 0 {
+			desc += " " + strings.Join(p.Devices, ",")
+		}
+		descs = append(descs, desc)
+	}
+	return strings.Join(descs, "; ")
+}
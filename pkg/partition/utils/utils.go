@@ -22,6 +22,10 @@ type ServicePreState struct {
 
 var PreStateDB = make(map[string]ServicePreState)
 
+// PreStateHook, when set, is called with every newly recorded pre-state
+// before the service is stopped so it can be persisted
+var PreStateHook func(ServicePreState)
+
 // connect to the system D-Bus
 func getSystemdConn() (*dbus.Conn, error) {
 	conn, err := dbus.SystemBus()
@@ -121,6 +125,9 @@ func StopServiceHandler(services []string) {
 				Timestamp: time.Now(),
 				Comment:   fmt.Sprintf("Service was %s before StopService", currStatus),
 			}
+			if PreStateHook != nil {
+				PreStateHook(PreStateDB[svc])
+			}
 		}
 
 		// when determining whether to stop the service